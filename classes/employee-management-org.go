@@ -0,0 +1,149 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEmployeeNotFound = errors.New("employee not registered")
+	ErrNoCommonManager  = errors.New("employees have no common manager")
+	ErrManagerCycle     = errors.New("management chain loops back on itself")
+)
+
+// Report is an employee somewhere below a manager, Depth 1 being a direct report
+type Report struct {
+	Employee *Employee
+	Depth    int
+}
+
+func (s *System) getEmployee(empId int) (*Employee, error) {
+	employee, ok := s.employeeMap[empId]
+	if !ok {
+		return nil, fmt.Errorf("%w: id %d", ErrEmployeeNotFound, empId)
+	}
+	return employee, nil
+}
+
+// GetChainOfCommand returns the managers above an employee, the direct manager first and the top last.
+// It costs one map lookup per level.
+func (s *System) GetChainOfCommand(empId int) ([]*Employee, error) {
+	employee, err := s.getEmployee(empId)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []*Employee
+	for employee.GetManagerId() != 0 {
+		manager, ok := s.employeeMap[employee.GetManagerId()]
+		if !ok {
+			break
+		}
+		// a chain longer than the org itself must have gone round in a circle
+		if len(chain) == len(s.employees) {
+			return nil, fmt.Errorf("%w: above %s", ErrManagerCycle, s.employeeMap[empId].GetName())
+		}
+		chain = append(chain, manager)
+		employee = manager
+	}
+	return chain, nil
+}
+
+// GetDepth is how many managers are above an employee, 0 for the top of the org
+func (s *System) GetDepth(empId int) (int, error) {
+	chain, err := s.GetChainOfCommand(empId)
+	return len(chain), err
+}
+
+// GetAllReports returns everyone below a manager breadth first, so direct reports come before
+// their own reports. It visits each report once, without recursion, so deep orgs are fine too.
+func (s *System) GetAllReports(empId int) ([]Report, error) {
+	manager, err := s.getEmployee(empId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reports found so far double as the queue, the manager sits in front of them
+	queue := []Report{{Employee: manager, Depth: 0}}
+	seen := map[int]bool{manager.GetId(): true}
+	for i := 0; i < len(queue); i++ {
+		for _, subordinate := range queue[i].Employee.GetSubordinates() {
+			if seen[subordinate.GetId()] {
+				continue
+			}
+			seen[subordinate.GetId()] = true
+			queue = append(queue, Report{Employee: subordinate, Depth: queue[i].Depth + 1})
+		}
+	}
+	return queue[1:], nil
+}
+
+// GetLowestCommonManager returns the lowest employee both employees report to, directly or not.
+// If one of them manages the other, that one is the answer.
+func (s *System) GetLowestCommonManager(firstId, secondId int) (*Employee, error) {
+	first, err := s.getEmployee(firstId)
+	if err != nil {
+		return nil, err
+	}
+	second, err := s.getEmployee(secondId)
+	if err != nil {
+		return nil, err
+	}
+	firstChain, err := s.GetChainOfCommand(firstId)
+	if err != nil {
+		return nil, err
+	}
+	secondChain, err := s.GetChainOfCommand(secondId)
+	if err != nil {
+		return nil, err
+	}
+
+	above := map[int]bool{first.GetId(): true}
+	for _, manager := range firstChain {
+		above[manager.GetId()] = true
+	}
+	for _, candidate := range append([]*Employee{second}, secondChain...) {
+		if above[candidate.GetId()] {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s and %s", ErrNoCommonManager, first.GetName(), second.GetName())
+}
+
+func EmployeeOrgChart() {
+	system := NewSystem()
+	names := []string{"Zeus", "Athena", "Apollo", "Ares", "Artemis", "Hermes", "Hades"}
+	employees := make(map[string]*Employee)
+	for _, name := range names {
+		employees[name] = NewEmployee(name)
+		system.RegisterEmployee(employees[name])
+	}
+	for _, pair := range [][2]string{
+		{"Athena", "Zeus"}, {"Apollo", "Zeus"}, {"Ares", "Athena"}, {"Artemis", "Apollo"}, {"Hermes", "Ares"},
+	} {
+		system.RegisterManager(employees[pair[0]].GetId(), employees[pair[1]].GetId())
+	}
+
+	chain, _ := system.GetChainOfCommand(employees["Hermes"].GetId())
+	fmt.Print("Hermes reports to:")
+	for _, manager := range chain {
+		fmt.Print(" ", manager.GetName())
+	}
+	fmt.Println()
+
+	depth, _ := system.GetDepth(employees["Hermes"].GetId())
+	fmt.Println("Depth of Hermes:", depth)
+	fmt.Println("********************************************************************")
+
+	reports, _ := system.GetAllReports(employees["Zeus"].GetId())
+	for _, report := range reports {
+		fmt.Printf("%s%s\n", strings.Repeat("  ", report.Depth-1), report.Employee.GetName())
+	}
+	fmt.Println("********************************************************************")
+
+	common, _ := system.GetLowestCommonManager(employees["Hermes"].GetId(), employees["Artemis"].GetId())
+	fmt.Println("Lowest common manager of Hermes and Artemis:", common.GetName())
+	_, err := system.GetLowestCommonManager(employees["Hermes"].GetId(), employees["Hades"].GetId())
+	fmt.Println(err)
+}
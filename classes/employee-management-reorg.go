@@ -0,0 +1,174 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrDuplicateMove = errors.New("employee is moved more than once")
+
+// ReassignError tells which move was rejected and why, ManagerId 0 meaning no manager
+type ReassignError struct {
+	EmployeeId int
+	ManagerId  int
+	Err        error
+}
+
+func (e *ReassignError) Error() string {
+	return fmt.Sprintf("cannot put employee %d under %d: %v", e.EmployeeId, e.ManagerId, e.Err)
+}
+
+func (e *ReassignError) Unwrap() error {
+	return e.Err
+}
+
+// Move puts an employee under a new manager, ManagerId 0 takes them to the top of the org
+type Move struct {
+	EmployeeId int
+	ManagerId  int
+}
+
+func (e *Employee) RemoveSubordinate(empId int) {
+	e.subordinates = slices.DeleteFunc(e.subordinates, func(subordinate *Employee) bool {
+		return subordinate.GetId() == empId
+	})
+}
+
+// ReassignManager moves an employee, with everyone below them, under a new manager and off the
+// list of the old one. Nothing changes if the manager is the employee or one of their reports.
+func (s *System) ReassignManager(empId int, managerId int) error {
+	_, err := s.reorg([]Move{{EmployeeId: empId, ManagerId: managerId}})
+	return err
+}
+
+// Reorg applies many moves at once. They are checked against the org as it would be after all
+// of them, so a move may rely on another one in the same batch, and either every move is applied
+// or none is.
+func (s *System) Reorg(moves []Move) error {
+	if i, err := s.reorg(moves); err != nil {
+		return fmt.Errorf("move %d: %w", i+1, err)
+	}
+	return nil
+}
+
+// reorg returns the index of the move that was rejected along with the reason
+func (s *System) reorg(moves []Move) (int, error) {
+	managers := make(map[int]int, len(moves))
+	for i, move := range moves {
+		if err := s.checkMove(move, managers); err != nil {
+			return i, err
+		}
+		managers[move.EmployeeId] = move.ManagerId
+	}
+
+	// only moved employees have a new manager, so a new loop has to run through one of them
+	settled := make(map[int]bool)
+	for i, move := range moves {
+		path := make(map[int]bool)
+		for id := move.EmployeeId; id != 0 && !settled[id]; id = s.managerAfter(id, managers) {
+			if path[id] {
+				return i, &ReassignError{EmployeeId: move.EmployeeId, ManagerId: move.ManagerId, Err: ErrManagerCycle}
+			}
+			path[id] = true
+		}
+		for id := range path {
+			settled[id] = true
+		}
+	}
+
+	for _, move := range moves {
+		employee := s.employeeMap[move.EmployeeId]
+		if old, ok := s.employeeMap[employee.GetManagerId()]; ok {
+			old.RemoveSubordinate(employee.GetId())
+		}
+		employee.SetManagerId(move.ManagerId)
+		if manager, ok := s.employeeMap[move.ManagerId]; ok {
+			manager.AddSubordinate(employee)
+		}
+	}
+	return 0, nil
+}
+
+func (s *System) checkMove(move Move, managers map[int]int) error {
+	reject := func(err error) error {
+		return &ReassignError{EmployeeId: move.EmployeeId, ManagerId: move.ManagerId, Err: err}
+	}
+	if _, err := s.getEmployee(move.EmployeeId); err != nil {
+		return reject(err)
+	}
+	if move.ManagerId != 0 {
+		if _, err := s.getEmployee(move.ManagerId); err != nil {
+			return reject(err)
+		}
+	}
+	if _, ok := managers[move.EmployeeId]; ok {
+		return reject(ErrDuplicateMove)
+	}
+	if move.EmployeeId == move.ManagerId {
+		return reject(fmt.Errorf("%w: an employee cannot manage themselves", ErrManagerCycle))
+	}
+	return nil
+}
+
+// managerAfter is the manager of an employee once the pending moves are applied
+func (s *System) managerAfter(empId int, managers map[int]int) int {
+	if managerId, ok := managers[empId]; ok {
+		return managerId
+	}
+	return s.employeeMap[empId].GetManagerId()
+}
+
+func EmployeeReorg() {
+	system := NewSystem()
+	names := []string{"Odin", "Thor", "Loki", "Freya", "Baldur"}
+	employees := make(map[string]*Employee)
+	for _, name := range names {
+		employees[name] = NewEmployee(name)
+		system.RegisterEmployee(employees[name])
+	}
+	id := func(name string) int {
+		return employees[name].GetId()
+	}
+	system.RegisterManager(id("Thor"), id("Odin"))
+	system.RegisterManager(id("Loki"), id("Odin"))
+	system.RegisterManager(id("Baldur"), id("Thor"))
+
+	printTeams := func() {
+		for _, name := range names {
+			fmt.Printf("%s:", name)
+			for _, subordinate := range employees[name].GetSubordinates() {
+				fmt.Print(" ", subordinate.GetName())
+			}
+			fmt.Println()
+		}
+	}
+
+	fmt.Println("** Moving Baldur from Thor to Loki **")
+	system.ReassignManager(id("Baldur"), id("Loki"))
+	printTeams()
+	fmt.Println("********************************************************************")
+
+	fmt.Println("** Odin cannot report to Baldur, who is below him **")
+	err := system.ReassignManager(id("Odin"), id("Baldur"))
+	fmt.Println(err, errors.Is(err, ErrManagerCycle))
+	fmt.Println("********************************************************************")
+
+	fmt.Println("** Freya takes over from Odin, who moves under her, in one reorg **")
+	err = system.Reorg([]Move{
+		{EmployeeId: id("Odin"), ManagerId: id("Freya")},
+		{EmployeeId: id("Thor"), ManagerId: id("Freya")},
+		{EmployeeId: id("Loki"), ManagerId: id("Freya")},
+	})
+	fmt.Println("error:", err)
+	printTeams()
+	fmt.Println("********************************************************************")
+
+	fmt.Println("** A reorg with a loop is rejected as a whole **")
+	err = system.Reorg([]Move{
+		{EmployeeId: id("Baldur"), ManagerId: id("Thor")},
+		{EmployeeId: id("Thor"), ManagerId: id("Baldur")},
+	})
+	fmt.Println(err)
+	printTeams()
+}
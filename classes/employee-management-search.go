@@ -0,0 +1,176 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrAmbiguousName = errors.New("more than one employee has that name")
+
+// nameTrie indexes employees by every word of their name, lower cased, so "Mary Jane Watson"
+// is found by "mar", "jane" and "wat". Employees with the same name simply share the node.
+type nameTrie struct {
+	children map[rune]*nameTrie
+	ids      []int
+}
+
+func newNameTrie() *nameTrie {
+	return &nameTrie{children: make(map[rune]*nameTrie)}
+}
+
+func (t *nameTrie) add(name string, empId int) {
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		node := t
+		for _, r := range word {
+			child, ok := node.children[r]
+			if !ok {
+				child = newNameTrie()
+				node.children[r] = child
+			}
+			node = child
+		}
+		if !slices.Contains(node.ids, empId) {
+			node.ids = append(node.ids, empId)
+		}
+	}
+}
+
+// find returns the node for a lower cased prefix, nil if no word starts with it
+func (t *nameTrie) find(prefix string) *nameTrie {
+	node := t
+	for _, r := range prefix {
+		node = node.children[r]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// collect returns the ids below the node that are not in seen yet and adds them to it
+func (t *nameTrie) collect(seen map[int]bool) []int {
+	var ids []int
+	stack := []*nameTrie{t}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, id := range node.ids {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		for _, child := range node.children {
+			stack = append(stack, child)
+		}
+	}
+	return ids
+}
+
+// SearchByName returns the employees with a first, middle or last name starting with prefix,
+// ignoring case, ordered by name and then by id so employees with the same name keep a stable
+// order. It skips offset results and returns at most limit of them, a limit of 0 meaning all,
+// along with the total number of matches for paging through the rest.
+func (s *System) SearchByName(prefix string, offset, limit int) ([]*Employee, int) {
+	words := strings.Fields(strings.ToLower(prefix))
+	if len(words) == 0 {
+		return nil, 0
+	}
+
+	// every word of the prefix has to start some word of the name, "mary wat" finds Mary Jane Watson
+	var ids []int
+	for i, word := range words {
+		node := s.names.find(word)
+		if node == nil {
+			return nil, 0
+		}
+		found := make(map[int]bool)
+		if i == 0 {
+			ids = node.collect(found)
+			continue
+		}
+		node.collect(found)
+		ids = slices.DeleteFunc(ids, func(id int) bool {
+			return !found[id]
+		})
+	}
+
+	matches := make([]*Employee, 0, len(ids))
+	for _, id := range ids {
+		matches = append(matches, s.employeeMap[id])
+	}
+	slices.SortFunc(matches, func(a, b *Employee) int {
+		if c := strings.Compare(strings.ToLower(a.GetName()), strings.ToLower(b.GetName())); c != 0 {
+			return c
+		}
+		return a.GetId() - b.GetId()
+	})
+
+	total := len(matches)
+	if offset >= total || offset < 0 {
+		return nil, total
+	}
+	matches = matches[offset:]
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	return matches, total
+}
+
+// GetEmployeesByName returns every employee with exactly that name, ignoring case, ordered by id
+func (s *System) GetEmployeesByName(name string) []*Employee {
+	matches, _ := s.SearchByName(name, 0, 0)
+	return slices.DeleteFunc(matches, func(employee *Employee) bool {
+		return !strings.EqualFold(employee.GetName(), strings.Join(strings.Fields(name), " "))
+	})
+}
+
+// GetEmployeeByName returns the one employee with that name, failing with ErrAmbiguousName
+// rather than picking one when there are several
+func (s *System) GetEmployeeByName(name string) (*Employee, error) {
+	matches := s.GetEmployeesByName(name)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrEmployeeNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("%w: %d employees are called %s", ErrAmbiguousName, len(matches), name)
+}
+
+func EmployeeNameSearch() {
+	system := NewSystem()
+	for _, name := range []string{
+		"Peter Parker", "Mary Jane Watson", "Harry Osborn", "Norman Osborn", "Ben Parker",
+		"May Parker", "Peter Quill", "Gwen Stacy", "Peter Parker",
+	} {
+		system.RegisterEmployee(NewEmployee(name))
+	}
+	printPage := func(employees []*Employee, total int) {
+		for _, employee := range employees {
+			fmt.Printf("Id: %d\tName: %s\n", employee.GetId(), employee.GetName())
+		}
+		fmt.Printf("(%d of %d)\n", len(employees), total)
+	}
+
+	fmt.Println("** Everyone named par..., first page of 2 **")
+	printPage(system.SearchByName("par", 0, 2))
+	fmt.Println("** Second page **")
+	printPage(system.SearchByName("par", 2, 2))
+	fmt.Println("********************************************************************")
+
+	fmt.Println("** First and last names together, in any case **")
+	printPage(system.SearchByName("MA WAT", 0, 0))
+	fmt.Println("********************************************************************")
+
+	fmt.Println("** There are two Peter Parkers **")
+	for _, employee := range system.GetEmployeesByName("peter parker") {
+		fmt.Printf("Id: %d\tName: %s\n", employee.GetId(), employee.GetName())
+	}
+	_, err := system.GetEmployeeByName("Peter Parker")
+	fmt.Println(err)
+	quill, _ := system.GetEmployeeByName("peter quill")
+	fmt.Println(quill.GetId(), quill.GetName())
+}
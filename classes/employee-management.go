@@ -1,9 +1,6 @@
 package classes
 
-import (
-	"fmt"
-	"strings"
-)
+import "fmt"
 
 type Employee struct {
 	id           int
@@ -15,6 +12,7 @@ type Employee struct {
 type System struct {
 	employees   []*Employee
 	employeeMap map[int]*Employee
+	names       *nameTrie
 }
 
 func NewEmployee(name string) *Employee {
@@ -60,24 +58,27 @@ func (e *Employee) AddSubordinate(subordinate *Employee) {
 func NewSystem() *System {
 	return &System{
 		employeeMap: make(map[int]*Employee),
+		names:       newNameTrie(),
 	}
 }
 
 func (s *System) RegisterEmployee(employee *Employee) {
 	s.employees = append(s.employees, employee)
 	s.employeeMap[employee.GetId()] = employee
+	s.names.add(employee.GetName(), employee.GetId())
 }
 
 func (s *System) RegisterManager(empId int, managerId int) {
-	employee, empOk := s.employeeMap[empId]
-	manager, mgrOk := s.employeeMap[managerId]
+	_, empOk := s.employeeMap[empId]
+	_, mgrOk := s.employeeMap[managerId]
 	if !empOk || !mgrOk {
 		fmt.Println("Either Employee or Manager not registered! Please provide correct registered identifiers to continue")
 		return
 	}
 
-	employee.SetManagerId(managerId)
-	manager.AddSubordinate(employee)
+	if err := s.ReassignManager(empId, managerId); err != nil {
+		fmt.Println(err)
+	}
 }
 
 func (s *System) PrintDetails(empId int) {
@@ -97,15 +98,14 @@ func (s *System) PrintDetails(empId int) {
 }
 
 func (s *System) PrintDetailsByPrefix(prefix string) {
-	for _, employee := range s.employees {
-		if strings.HasPrefix(employee.GetName(), prefix) {
-			fmt.Printf("Id: %d\tName: %s\t", employee.GetId(), employee.GetName())
-			if employee.GetManagerId() != 0 {
-				manager := s.employeeMap[employee.GetManagerId()]
-				fmt.Printf("Manager: %s\n", manager.GetName())
-			} else {
-				fmt.Println("Manager: None")
-			}
+	employees, _ := s.SearchByName(prefix, 0, 0)
+	for _, employee := range employees {
+		fmt.Printf("Id: %d\tName: %s\t", employee.GetId(), employee.GetName())
+		if employee.GetManagerId() != 0 {
+			manager := s.employeeMap[employee.GetManagerId()]
+			fmt.Printf("Manager: %s\n", manager.GetName())
+		} else {
+			fmt.Println("Manager: None")
 		}
 	}
 }
@@ -120,12 +120,12 @@ func (s *System) GetSubordinates(empId int) []*Employee {
 }
 
 func (s *System) GetSubordinatesByName(name string) []*Employee {
-	for _, employee := range s.employees {
-		if employee.GetName() == name {
-			return employee.GetSubordinates()
-		}
+	employee, err := s.GetEmployeeByName(name)
+	if err != nil {
+		fmt.Println(err)
+		return nil
 	}
-	return nil
+	return employee.GetSubordinates()
 }
 
 func EmployeeManagement() {
@@ -0,0 +1,196 @@
+package classes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const DEFAULT_CLIPBOARD_HISTORY = 10
+
+// Clipboard has the unnamed register every copy goes to, vim style named registers a-z and a
+// history of the most recent copies. Copying into A-Z appends to the matching lowercase register.
+type Clipboard struct {
+	unnamed     []string
+	registers   map[rune][]string
+	history     [][]string // most recent first
+	historySize int
+}
+
+func NewClipboard(historySize int) *Clipboard {
+	return &Clipboard{registers: make(map[rune][]string), historySize: historySize}
+}
+
+func isRegister(register rune) bool {
+	return (register >= 'a' && register <= 'z') || (register >= 'A' && register <= 'Z')
+}
+
+// yank stores lines in register, 0 meaning only the unnamed one, and records them in the history
+func (c *Clipboard) yank(register rune, lines []string) error {
+	if register != 0 && !isRegister(register) {
+		return fmt.Errorf("%w %q", ErrInvalidRegister, register)
+	}
+	lines = append([]string{}, lines...)
+
+	switch {
+	case register >= 'A' && register <= 'Z':
+		lower := register - 'A' + 'a'
+		c.registers[lower] = append(append([]string{}, c.registers[lower]...), lines...)
+	case register != 0:
+		c.registers[register] = lines
+	}
+	c.unnamed = lines
+
+	if c.historySize > 0 {
+		c.history = append([][]string{lines}, c.history...)
+		if len(c.history) > c.historySize {
+			c.history = c.history[:c.historySize]
+		}
+	}
+	return nil
+}
+
+// get reads a register, 0 is the unnamed one and A-Z read the same as a-z
+func (c *Clipboard) get(register rune) ([]string, error) {
+	if register == 0 {
+		if len(c.unnamed) == 0 {
+			return nil, ErrEmptyClipboard
+		}
+		return c.unnamed, nil
+	}
+	if !isRegister(register) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidRegister, register)
+	}
+	if register <= 'Z' {
+		register = register - 'A' + 'a'
+	}
+	lines, ok := c.registers[register]
+	if !ok {
+		return nil, fmt.Errorf("register %q: %w", register, ErrEmptyClipboard)
+	}
+	return lines, nil
+}
+
+// recent returns a copy from the history, 1 being the latest
+func (c *Clipboard) recent(index int) ([]string, error) {
+	if index < 1 || index > len(c.history) {
+		return nil, fmt.Errorf("%w: no copy number %d in the history", ErrEmptyClipboard, index)
+	}
+	return c.history[index-1], nil
+}
+
+// Register returns a copy of a named register's lines, or nil if it is empty
+func (c *Clipboard) Register(register rune) []string {
+	lines, err := c.get(register)
+	if err != nil {
+		return nil
+	}
+	return append([]string{}, lines...)
+}
+
+// copyRangeTo copies lines into a named register (A-Z to append) as well as the unnamed one
+func (n *Notepad) copyRangeTo(start, end int, register rune) error {
+	if err := n.checkRange(start, end); err != nil {
+		return n.fail(err)
+	}
+	if err := n.clipboard.yank(register, n.allContent.Lines(start-1, end)); err != nil {
+		return n.fail(err)
+	}
+	n.recordStep(MacroStep{Op: "copyRangeTo", Line: start, End: end, Register: registerName(register)})
+	return nil
+}
+
+// pasteFrom pastes a named register before line
+func (n *Notepad) pasteFrom(line int, register rune) error {
+	lines, err := n.clipboard.get(register)
+	if err != nil {
+		return n.fail(err)
+	}
+	if err := n.pasteLines(line, lines); err != nil {
+		return err
+	}
+	n.recordStep(MacroStep{Op: "pasteFrom", Line: line, Register: registerName(register)})
+	return nil
+}
+
+// pasteRecent pastes one of the last copies before line, 1 being the latest
+func (n *Notepad) pasteRecent(line int, index int) error {
+	lines, err := n.clipboard.recent(index)
+	if err != nil {
+		return n.fail(err)
+	}
+	if err := n.pasteLines(line, lines); err != nil {
+		return err
+	}
+	n.recordStep(MacroStep{Op: "pasteRecent", Line: line, Index: index})
+	return nil
+}
+
+func (n *Notepad) GetClipboard() *Clipboard {
+	return n.clipboard
+}
+
+type clipboardState struct {
+	Registers map[string][]string `json:"registers"`
+	History   [][]string          `json:"history"`
+}
+
+// registersPath is the hidden file next to a document that keeps its registers between sessions
+func registersPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".registers")
+}
+
+// save writes the registers next to path, unless nothing was ever copied
+func (c *Clipboard) save(path string) error {
+	if len(c.registers) == 0 && len(c.history) == 0 {
+		return nil
+	}
+	state := clipboardState{Registers: make(map[string][]string), History: c.history}
+	for register, lines := range c.registers {
+		state.Registers[string(register)] = lines
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(registersPath(path), data)
+}
+
+// RegistersErr returns why the registers could not be saved next to the file on the last save,
+// the document itself was saved all the same
+func (n *Notepad) RegistersErr() error {
+	return n.registersErr
+}
+
+// load restores the registers saved next to path, a missing file just means there is nothing to restore
+func (c *Clipboard) load(path string) error {
+	data, err := os.ReadFile(registersPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state clipboardState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid registers file: %w", err)
+	}
+	for name, lines := range state.Registers {
+		register := []rune(name)
+		if len(register) == 1 && register[0] >= 'a' && register[0] <= 'z' {
+			c.registers[register[0]] = lines
+		}
+	}
+	c.history = state.History
+	if len(c.history) > c.historySize {
+		c.history = c.history[:c.historySize]
+	}
+	if len(c.history) > 0 {
+		c.unnamed = c.history[0]
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package classes
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+)
+
+// CollabID names one line ever inserted into a shared document. Clock is a Lamport clock, so an
+// id is always larger than the ids of the lines its replica had seen when it was created.
+type CollabID struct {
+	Clock   int
+	Replica string
+}
+
+func (a CollabID) after(b CollabID) bool {
+	if a.Clock != b.Clock {
+		return a.Clock > b.Clock
+	}
+	return a.Replica > b.Replica
+}
+
+type CollabOpKind int
+
+const (
+	COLLAB_INSERT CollabOpKind = iota
+	COLLAB_DELETE
+)
+
+// CollabOp is what replicas send each other. An insert places line ID right after line After
+// (the zero id is the start of the document), a delete hides line ID.
+type CollabOp struct {
+	Kind  CollabOpKind
+	ID    CollabID
+	After CollabID
+	Text  string
+}
+
+type collabLine struct {
+	id      CollabID
+	text    string
+	deleted bool
+	next    *collabLine
+}
+
+// Replica keeps a Notepad in sync with other replicas through a line based RGA (replicated growable
+// array), a sequence CRDT: every replica ends up with the same lines whatever order operations
+// arrive in, duplicates included. Edit the notepad as usual and call Sync to turn the edits into
+// operations for the others. Remote operations reach the notepad as an ordinary edit, so marks follow
+// them and they take a step in the undo history: undoing one is a local edit the next Sync sends out.
+type Replica struct {
+	id      string
+	clock   int
+	head    *collabLine // sentinel before the first line
+	lines   map[CollabID]*collabLine
+	pending []CollabOp // received operations not applied yet, see Sync
+	outbox  []CollabOp
+	notepad *Notepad
+}
+
+// NewReplica starts a replica from the notepad's current lines, replicas of one document must all
+// start from the same content so they agree on the ids of the initial lines
+func NewReplica(id string, n *Notepad) *Replica {
+	r := &Replica{id: id, head: &collabLine{}, lines: make(map[CollabID]*collabLine), notepad: n}
+	previous := r.head
+	for i, text := range n.lines() {
+		line := &collabLine{id: CollabID{Clock: i + 1}, text: text}
+		previous.next = line
+		r.lines[line.id] = line
+		previous = line
+	}
+	r.clock = n.allContent.Len()
+	return r
+}
+
+func (r *Replica) GetId() string {
+	return r.id
+}
+
+func (r *Replica) GetNotepad() *Notepad {
+	return r.notepad
+}
+
+func (r *Replica) visible() []*collabLine {
+	var lines []*collabLine
+	for line := r.head.next; line != nil; line = line.next {
+		if !line.deleted {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Lines is the replicated content, after Sync it matches the notepad
+func (r *Replica) Lines() []string {
+	var texts []string
+	for _, line := range r.visible() {
+		texts = append(texts, line.text)
+	}
+	return texts
+}
+
+// Sync compares the notepad with the replicated content and records the local edits as operations,
+// the changed middle between the common prefix and suffix is deleted and inserted again. It then
+// applies the received operations whose line has arrived. Nothing happens while the notepad has a
+// transaction open, so uncommitted edits are never sent and the lines under it never move.
+func (r *Replica) Sync() {
+	if r.notepad.InTransaction() {
+		return
+	}
+	r.record()
+	r.receivePending()
+}
+
+func (r *Replica) record() {
+	current := r.notepad.lines()
+	visible := r.visible()
+
+	prefix := 0
+	for prefix < len(current) && prefix < len(visible) && current[prefix] == visible[prefix].text {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(current)-prefix && suffix < len(visible)-prefix &&
+		current[len(current)-1-suffix] == visible[len(visible)-1-suffix].text {
+		suffix++
+	}
+
+	for _, line := range visible[prefix : len(visible)-suffix] {
+		r.emit(CollabOp{Kind: COLLAB_DELETE, ID: line.id})
+	}
+	after := CollabID{}
+	if prefix > 0 {
+		after = visible[prefix-1].id
+	}
+	for _, text := range current[prefix : len(current)-suffix] {
+		r.clock++
+		id := CollabID{Clock: r.clock, Replica: r.id}
+		r.emit(CollabOp{Kind: COLLAB_INSERT, ID: id, After: after, Text: text})
+		after = id
+	}
+}
+
+func (r *Replica) emit(op CollabOp) {
+	r.apply(op)
+	r.outbox = append(r.outbox, op)
+}
+
+// TakeOutbox hands over the operations produced since the last call
+func (r *Replica) TakeOutbox() []CollabOp {
+	ops := r.outbox
+	r.outbox = nil
+	return ops
+}
+
+// Receive takes an operation from another replica and syncs, local edits not synced yet are recorded
+// before it is applied. During a transaction it waits until a Sync after Commit or Rollback.
+func (r *Replica) Receive(op CollabOp) {
+	r.pending = append(r.pending, op)
+	r.Sync()
+}
+
+func (r *Replica) receivePending() {
+	before := r.Lines()
+	for progress := true; progress; {
+		progress = false
+		waiting := r.pending[:0]
+		for _, op := range r.pending {
+			if r.ready(op) {
+				r.apply(op)
+				progress = true
+			} else {
+				waiting = append(waiting, op)
+			}
+		}
+		r.pending = waiting
+	}
+
+	if ops := spliceOps(before, r.Lines()); len(ops) > 0 {
+		r.notepad.do(&groupOp{ops: ops})
+	}
+}
+
+// spliceOps turns the difference between two versions of a document into splices that applied in
+// order change the first into the second, one per run of changed lines
+func spliceOps(from, to []string) []editOp {
+	var ops []editOp
+	edits := diffLines(from, to)
+	line := 1
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			line++
+			i++
+			continue
+		}
+		op := &spliceOp{line: line}
+		for ; i < len(edits) && edits[i].kind != ' '; i++ {
+			if edits[i].kind == '-' {
+				op.removed = append(op.removed, edits[i].text)
+			} else {
+				op.inserted = append(op.inserted, edits[i].text)
+			}
+		}
+		ops = append(ops, op)
+		line += len(op.inserted)
+	}
+	return ops
+}
+
+func (r *Replica) ready(op CollabOp) bool {
+	if op.Kind == COLLAB_DELETE {
+		_, ok := r.lines[op.ID]
+		return ok
+	}
+	_, ok := r.lines[op.After]
+	return ok || op.After == CollabID{}
+}
+
+func (r *Replica) apply(op CollabOp) {
+	if op.ID.Clock > r.clock {
+		r.clock = op.ID.Clock
+	}
+
+	if op.Kind == COLLAB_DELETE {
+		r.lines[op.ID].deleted = true
+		return
+	}
+	if _, duplicate := r.lines[op.ID]; duplicate {
+		return
+	}
+
+	// lines inserted concurrently at the same spot are ordered by id, newest first, skipping past
+	// them also skips past anything inserted after them since those ids are larger still
+	previous := r.head
+	if op.After != (CollabID{}) {
+		previous = r.lines[op.After]
+	}
+	for previous.next != nil && previous.next.id.after(op.ID) {
+		previous = previous.next
+	}
+	line := &collabLine{id: op.ID, text: op.Text, next: previous.next}
+	previous.next = line
+	r.lines[op.ID] = line
+}
+
+type collabMessage struct {
+	to    *Replica
+	op    CollabOp
+	delay int
+}
+
+// CollabNetwork is an in-process network for replicas that delivers every operation to every other
+// replica, but late, out of order and sometimes twice, driven by a seeded random source
+type CollabNetwork struct {
+	replicas      []*Replica
+	inFlight      []collabMessage
+	rng           *rand.Rand
+	maxDelay      int
+	duplicateRate float64
+}
+
+func NewCollabNetwork(seed int64, maxDelay int, duplicateRate float64, replicas ...*Replica) *CollabNetwork {
+	return &CollabNetwork{
+		replicas:      replicas,
+		rng:           rand.New(rand.NewSource(seed)),
+		maxDelay:      maxDelay,
+		duplicateRate: duplicateRate,
+	}
+}
+
+// Send syncs every replica and puts their new operations on the wire
+func (net *CollabNetwork) Send() {
+	for _, from := range net.replicas {
+		from.Sync()
+		for _, op := range from.TakeOutbox() {
+			for _, to := range net.replicas {
+				if to == from {
+					continue
+				}
+				net.inFlight = append(net.inFlight, collabMessage{to: to, op: op, delay: net.rng.Intn(net.maxDelay + 1)})
+				if net.rng.Float64() < net.duplicateRate {
+					net.inFlight = append(net.inFlight, collabMessage{to: to, op: op, delay: net.rng.Intn(net.maxDelay + 1)})
+				}
+			}
+		}
+	}
+}
+
+// Step lets one tick pass and delivers the messages that are due in random order, it reports
+// whether anything is still in flight
+func (net *CollabNetwork) Step() bool {
+	var due, later []collabMessage
+	for _, message := range net.inFlight {
+		if message.delay <= 0 {
+			due = append(due, message)
+		} else {
+			message.delay--
+			later = append(later, message)
+		}
+	}
+	net.inFlight = later
+
+	net.rng.Shuffle(len(due), func(i, j int) { due[i], due[j] = due[j], due[i] })
+	for _, message := range due {
+		message.to.Receive(message.op)
+	}
+	return len(net.inFlight) > 0
+}
+
+// Settle keeps sending and delivering until no replica has anything left to send
+func (net *CollabNetwork) Settle() {
+	for net.Send(); len(net.inFlight) > 0; net.Send() {
+		for net.Step() {
+		}
+	}
+}
+
+// Converged reports whether every replica holds the same lines
+func (net *CollabNetwork) Converged() bool {
+	for _, replica := range net.replicas[1:] {
+		if !slices.Equal(replica.Lines(), net.replicas[0].Lines()) {
+			return false
+		}
+	}
+	return true
+}
+
+func NotePadCollab() {
+	text := "Shopping list\nmilk\nbread"
+	alice := NewReplica("alice", NewNotepad(text))
+	bob := NewReplica("bob", NewNotepad(text))
+	carol := NewReplica("carol", NewNotepad(text))
+	net := NewCollabNetwork(42, 3, 0.3, alice, bob, carol)
+
+	alice.GetNotepad().insert(2, " (oat)")
+	bob.GetNotepad().deleteLine(3)
+	carol.GetNotepad().insertLines(4, "eggs", "coffee")
+	net.Settle()
+
+	for _, replica := range []*Replica{alice, bob, carol} {
+		fmt.Printf("%s: %q\n", replica.GetId(), replica.Lines())
+	}
+	fmt.Println("Converged:", net.Converged())
+}
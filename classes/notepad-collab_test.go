@@ -0,0 +1,77 @@
+package classes
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// TestCollabConvergence has replicas edit concurrently over a network that delays, reorders and
+// duplicates their operations, after settling every replica must show the same document
+func TestCollabConvergence(t *testing.T) {
+	tests := []struct {
+		name          string
+		replicas      int
+		maxDelay      int
+		duplicateRate float64
+	}{
+		{name: "two replicas, instant delivery", replicas: 2, maxDelay: 0, duplicateRate: 0},
+		{name: "three replicas, delayed", replicas: 3, maxDelay: 3, duplicateRate: 0},
+		{name: "three replicas, delayed and duplicated", replicas: 3, maxDelay: 3, duplicateRate: 0.3},
+		{name: "five replicas, long delays and duplicated", replicas: 5, maxDelay: 10, duplicateRate: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				rng := rand.New(rand.NewSource(seed))
+				var replicas []*Replica
+				for i := 0; i < tt.replicas; i++ {
+					replicas = append(replicas, NewReplica(fmt.Sprintf("r%d", i), NewNotepad("one\ntwo\nthree")))
+				}
+				net := NewCollabNetwork(seed, tt.maxDelay, tt.duplicateRate, replicas...)
+
+				for round := 0; round < 20; round++ {
+					for _, replica := range replicas {
+						for edits := rng.Intn(3); edits > 0; edits-- {
+							randomCollabEdit(rng, replica.GetNotepad())
+						}
+					}
+					// deliver only part of the traffic so the next round edits concurrently with it
+					net.Send()
+					for steps := rng.Intn(3); steps > 0; steps-- {
+						net.Step()
+					}
+				}
+				net.Settle()
+
+				if !net.Converged() {
+					t.Fatalf("seed %d: replicas did not converge", seed)
+				}
+				for _, replica := range replicas {
+					if got, want := replica.GetNotepad().lines(), replica.Lines(); !slices.Equal(got, want) {
+						t.Fatalf("seed %d: notepad of %s shows %q, replica has %q", seed, replica.GetId(), got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// randomCollabEdit makes one local edit, invalid lines are simply refused by the notepad
+func randomCollabEdit(rng *rand.Rand, n *Notepad) {
+	line := rng.Intn(n.allContent.Len()+1) + 1
+	switch rng.Intn(5) {
+	case 0:
+		n.insert(line, fmt.Sprint(rng.Intn(100)))
+	case 1:
+		n.insertLines(line, fmt.Sprint("new ", rng.Intn(100)))
+	case 2:
+		n.deleteLine(line)
+	case 3:
+		n.deleteRange(line, line+rng.Intn(3))
+	case 4:
+		n.undo()
+	}
+}
@@ -0,0 +1,114 @@
+package classes
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// columns count runes and start at 1, a line of n runes has columns 1 to n+1 where n+1 is the end of the line
+
+// byteOffset turns a rune column into a byte index into text, ok is false if the column is outside the line
+func byteOffset(text string, column int) (int, bool) {
+	if column < 1 || column > utf8.RuneCountInString(text)+1 {
+		return 0, false
+	}
+	offset := 0
+	for i := 1; i < column; i++ {
+		_, width := utf8.DecodeRuneInString(text[offset:])
+		offset += width
+	}
+	return offset, true
+}
+
+// insertAt puts text at a column, newlines in text split the line
+func (n *Notepad) insertAt(line, column int, text string) error {
+	if err := n.checkLine(line); err != nil {
+		return n.fail(err)
+	}
+	current := n.allContent.Line(line - 1)
+	offset, ok := byteOffset(current, column)
+	if !ok {
+		return n.fail(columnError(line, column, current))
+	}
+
+	inserted := split(current[:offset]+text+current[offset:], '\n')
+	n.do(&spliceOp{line: line, removed: []string{current}, inserted: inserted})
+	n.recordStep(MacroStep{Op: "insertAt", Line: line, Column: column, Text: text})
+	return nil
+}
+
+func columnError(line, column int, text string) error {
+	return fmt.Errorf("%w: column %d of line %d, which has %d", ErrColumnOutOfRange, column, line, utf8.RuneCountInString(text)+1)
+}
+
+// insertLines adds whole new lines before line, line can be one past the last line to add at the end
+func (n *Notepad) insertLines(line int, lines ...string) error {
+	if err := n.checkInsertLine(line); err != nil {
+		return n.fail(err)
+	}
+
+	n.do(&spliceOp{line: line, inserted: append([]string{}, lines...)})
+	n.recordStep(MacroStep{Op: "insertLines", Line: line, Lines: lines})
+	return nil
+}
+
+// span resolves a span from (startLine, startColumn) up to but not including (endLine, endColumn)
+// into the lines it covers and the byte offsets of its ends in the first and last of them
+func (n *Notepad) span(startLine, startColumn, endLine, endColumn int) ([]string, int, int, error) {
+	if err := n.checkRange(startLine, endLine); err != nil {
+		return nil, 0, 0, err
+	}
+	lines := n.allContent.Lines(startLine-1, endLine)
+	start, ok := byteOffset(lines[0], startColumn)
+	if !ok {
+		return nil, 0, 0, columnError(startLine, startColumn, lines[0])
+	}
+	end, ok := byteOffset(lines[len(lines)-1], endColumn)
+	if !ok {
+		return nil, 0, 0, columnError(endLine, endColumn, lines[len(lines)-1])
+	}
+	if startLine == endLine && start > end {
+		return nil, 0, 0, fmt.Errorf("%w: columns %d to %d", ErrEmptyRange, startColumn, endColumn)
+	}
+	return lines, start, end, nil
+}
+
+func spanText(lines []string, start, end int) string {
+	if len(lines) == 1 {
+		return lines[0][start:end]
+	}
+	middle := append([]string{lines[0][start:]}, lines[1:len(lines)-1]...)
+	return strings.Join(append(middle, lines[len(lines)-1][:end]), "\n")
+}
+
+// deleteSpan removes the characters of a span, deleting up to column 1 of the next line joins the two lines
+func (n *Notepad) deleteSpan(startLine, startColumn, endLine, endColumn int) error {
+	lines, start, end, err := n.span(startLine, startColumn, endLine, endColumn)
+	if err != nil {
+		return n.fail(err)
+	}
+
+	joined := lines[0][:start] + lines[len(lines)-1][end:]
+	n.do(&spliceOp{line: startLine, removed: lines, inserted: []string{joined}})
+	n.recordStep(MacroStep{Op: "deleteSpan", Line: startLine, Column: startColumn, End: endLine, EndColumn: endColumn})
+	return nil
+}
+
+// cut copies a span to the clipboard and deletes it, the delete is the only undo step
+func (n *Notepad) cut(startLine, startColumn, endLine, endColumn int) error {
+	lines, start, end, err := n.span(startLine, startColumn, endLine, endColumn)
+	if err != nil {
+		return n.fail(err)
+	}
+
+	n.clipboard.yank(0, split(spanText(lines, start, end), '\n'))
+	resume := n.pauseRecording()
+	err = n.deleteSpan(startLine, startColumn, endLine, endColumn)
+	resume()
+	if err != nil {
+		return err
+	}
+	n.recordStep(MacroStep{Op: "cut", Line: startLine, Column: startColumn, End: endLine, EndColumn: endColumn})
+	return nil
+}
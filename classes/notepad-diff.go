@@ -0,0 +1,465 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lines of unchanged context around each change in a unified diff
+const DIFF_CONTEXT = 3
+
+var (
+	ErrHunkFailed   = errors.New("hunk does not apply")
+	ErrInvalidPatch = errors.New("invalid patch")
+)
+
+// diffLine is one line of a diff, kind is ' ' for context, '-' for removed and '+' for added
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diffLines finds a shortest edit script turning a into b with Myers' O((N+M)D) algorithm
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []diffLine
+	for _, text := range a[:prefix] {
+		edits = append(edits, diffLine{' ', text})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		edits = append(edits, diffLine{' ', text})
+	}
+	return edits
+}
+
+func myers(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k, trace[d] keeps diagonals -d..d after d edits
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; ; d++ {
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+			}
+		}
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+		if done {
+			break
+		}
+	}
+
+	// walk back from the end, each step is a snake of equal lines preceded by one edit
+	var reversed []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d-1]
+		at := func(k int) int { return previous[k+d-1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		startX, startY := prevX, prevY+1
+		if prevK == k-1 {
+			startX, startY = prevX+1, prevY
+		}
+		for x > startX && y > startY {
+			x--
+			y--
+			reversed = append(reversed, diffLine{' ', a[x]})
+		}
+		if prevK == k+1 {
+			reversed = append(reversed, diffLine{'+', b[prevY]})
+		} else {
+			reversed = append(reversed, diffLine{'-', a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 {
+		x--
+		reversed = append(reversed, diffLine{' ', a[x]})
+	}
+
+	edits := make([]diffLine, len(reversed))
+	for i, edit := range reversed {
+		edits[len(reversed)-1-i] = edit
+	}
+	return edits
+}
+
+type diffHunk struct {
+	fromStart, fromCount int
+	toStart, toCount     int
+	lines                []diffLine
+}
+
+// hunks groups changes that are less than two contexts apart, each with context lines around it
+func hunks(edits []diffLine, context int) []diffHunk {
+	// from[i] and to[i] count the lines of each side before edits[i]
+	from := make([]int, len(edits)+1)
+	to := make([]int, len(edits)+1)
+	for i, edit := range edits {
+		from[i+1], to[i+1] = from[i], to[i]
+		if edit.kind != '+' {
+			from[i+1]++
+		}
+		if edit.kind != '-' {
+			to[i+1]++
+		}
+	}
+
+	var result []diffHunk
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		stop := min(end+context, len(edits))
+
+		hunk := diffHunk{
+			fromStart: from[start] + 1, fromCount: from[stop] - from[start],
+			toStart: to[start] + 1, toCount: to[stop] - to[start],
+			lines: edits[start:stop],
+		}
+		// an empty side is numbered by the line before it, as diff -u does
+		if hunk.fromCount == 0 {
+			hunk.fromStart--
+		}
+		if hunk.toCount == 0 {
+			hunk.toStart--
+		}
+		result = append(result, hunk)
+		i = stop
+	}
+	return result
+}
+
+// UnifiedDiff compares two versions line by line, it returns "" when they are the same
+func UnifiedDiff(fromName, toName string, from, to []string, context int) string {
+	found := hunks(diffLines(from, to), context)
+	if len(found) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range found {
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", hunk.fromStart, hunk.fromCount, hunk.toStart, hunk.toCount)
+		for _, line := range hunk.lines {
+			out.WriteByte(line.kind)
+			out.WriteString(line.text)
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+// DiffState shows what changed between undo state seq and the current content
+func (n *Notepad) DiffState(seq int) (string, error) {
+	target, ok := n.history.nodes[seq]
+	if !ok {
+		return "", fmt.Errorf("%w: no undo state %d", ErrNothingToUndo, seq)
+	}
+	return UnifiedDiff(fmt.Sprintf("state %d", seq), "current", n.linesAt(target), n.lines(), DIFF_CONTEXT), nil
+}
+
+// DiffNotepad shows what turns this notepad's content into the other's
+func (n *Notepad) DiffNotepad(other *Notepad) string {
+	return UnifiedDiff("this", "other", n.lines(), other.lines(), DIFF_CONTEXT)
+}
+
+// DiffFile shows what changed between the file on disk and the current content
+func (n *Notepad) DiffFile(path string) (string, error) {
+	lines, _, err := readDocument(path)
+	if err != nil {
+		return "", err
+	}
+	return UnifiedDiff(path, "current", lines, n.lines(), DIFF_CONTEXT), nil
+}
+
+// linesAt rebuilds the content of an undo state on a scratch copy, leaving the notepad and its redo path alone
+func (n *Notepad) linesAt(target *undoNode) []string {
+	scratch := NewNotepadWithStorage(NewSliceStorage(n.lines()))
+
+	ancestors := make(map[*undoNode]bool)
+	for node := target; node != nil; node = node.parent {
+		ancestors[node] = true
+	}
+	node := n.history.current
+	for ; !ancestors[node]; node = node.parent {
+		node.op.revert(scratch)
+	}
+	var down []*undoNode
+	for step := target; step != node; step = step.parent {
+		down = append(down, step)
+	}
+	for i := len(down) - 1; i >= 0; i-- {
+		down[i].op.apply(scratch)
+	}
+	return scratch.lines()
+}
+
+// HunkResult tells where a hunk of a patch went: Line is where it was applied, Offset how far that is
+// from the line the patch gives for the new version and Fuzz how many context lines had to be ignored.
+// Err is set if the hunk failed.
+type HunkResult struct {
+	Hunk   int
+	Line   int
+	Offset int
+	Fuzz   int
+	Err    error
+}
+
+// ApplyPatch applies a unified diff as a single undoable edit. Hunks are looked for near the line the
+// patch names and then further away, and with fuzz > 0 up to that many context lines at either end of
+// a hunk may be ignored. Hunks that still do not match are skipped and reported, the rest are applied.
+func (n *Notepad) ApplyPatch(patch string, fuzz int) ([]HunkResult, error) {
+	parsed, err := parsePatch(patch)
+	if err != nil {
+		return nil, n.fail(fmt.Errorf("%w: %w", ErrInvalidPatch, err))
+	}
+
+	original := n.lines()
+	work := NewNotepadWithStorage(NewSliceStorage(append([]string{}, original...)))
+	var ops []editOp
+	var results []HunkResult
+	failed := 0
+	shift, floor := 0, 0
+	for i, hunk := range parsed {
+		result := HunkResult{Hunk: i + 1}
+		expected := hunk.sourceIndex()
+
+		found := false
+		for f := 0; f <= fuzz && !found; f++ {
+			old, replacement, dropped, ok := hunk.trimContext(f)
+			if !ok {
+				break
+			}
+			want := expected + dropped + shift
+			if at, ok := findLines(work.allContent, old, want, floor); ok {
+				op := &spliceOp{line: at + 1, removed: old, inserted: replacement}
+				op.apply(work)
+				ops = append(ops, op)
+				result.Line, result.Offset, result.Fuzz = at+1, at-dropped-hunk.targetIndex(), f
+				shift = at - expected - dropped + len(replacement) - len(old)
+				floor = at + len(replacement)
+				found = true
+			}
+		}
+		if !found {
+			result.Err = fmt.Errorf("hunk %d at line %d: %w", i+1, hunk.fromStart, ErrHunkFailed)
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if len(ops) > 0 {
+		n.do(&groupOp{ops: ops})
+	}
+	// a macro gets the change that was actually made, a partly applied patch is recorded as the
+	// diff it produced so playing it back does not fail on the same hunks again
+	if failed == 0 {
+		n.recordStep(MacroStep{Op: "applyPatch", Patch: patch, Fuzz: fuzz})
+	} else if len(ops) > 0 && n.recording != nil {
+		applied := UnifiedDiff("a", "b", original, work.lines(), DIFF_CONTEXT)
+		n.recordStep(MacroStep{Op: "applyPatch", Patch: applied, Fuzz: fuzz})
+	}
+	if failed > 0 {
+		return results, n.fail(fmt.Errorf("%d of %d hunks failed: %w", failed, len(parsed), ErrHunkFailed))
+	}
+	return results, nil
+}
+
+// sourceIndex is where the hunk starts in the original, counting from 0
+func (h diffHunk) sourceIndex() int {
+	if h.fromCount == 0 {
+		return h.fromStart
+	}
+	return h.fromStart - 1
+}
+
+// targetIndex is where the hunk starts in the patched version, counting from 0
+func (h diffHunk) targetIndex() int {
+	if h.toCount == 0 {
+		return h.toStart
+	}
+	return h.toStart - 1
+}
+
+// trimContext drops up to fuzz context lines from each end, it fails once there is nothing left to drop
+func (h diffHunk) trimContext(fuzz int) ([]string, []string, int, bool) {
+	leading := 0
+	for leading < len(h.lines) && h.lines[leading].kind == ' ' {
+		leading++
+	}
+	trailing := 0
+	for trailing < len(h.lines)-leading && h.lines[len(h.lines)-1-trailing].kind == ' ' {
+		trailing++
+	}
+	if fuzz > 0 && leading < fuzz && trailing < fuzz {
+		return nil, nil, 0, false
+	}
+	dropLeading, dropTrailing := min(fuzz, leading), min(fuzz, trailing)
+
+	var old, replacement []string
+	for _, line := range h.lines[dropLeading : len(h.lines)-dropTrailing] {
+		if line.kind != '+' {
+			old = append(old, line.text)
+		}
+		if line.kind != '-' {
+			replacement = append(replacement, line.text)
+		}
+	}
+	return old, replacement, dropLeading, true
+}
+
+// findLines looks for lines at or after floor, trying positions closest to want first
+func findLines(storage TextStorage, lines []string, want, floor int) (int, bool) {
+	last := storage.Len() - len(lines)
+	matches := func(at int) bool {
+		if at < floor || at > last {
+			return false
+		}
+		for i, line := range lines {
+			if storage.Line(at+i) != line {
+				return false
+			}
+		}
+		return true
+	}
+	for distance := 0; want-distance >= floor || want+distance <= last; distance++ {
+		if matches(want - distance) {
+			return want - distance, true
+		}
+		if distance > 0 && matches(want+distance) {
+			return want + distance, true
+		}
+	}
+	return 0, false
+}
+
+// parsePatch reads the hunks of a unified diff, file headers and "\ No newline" markers are skipped
+func parsePatch(patch string) ([]diffHunk, error) {
+	var result []diffHunk
+	lines := strings.Split(strings.TrimSuffix(patch, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "@@ ") {
+			continue
+		}
+		hunk, err := parseHunkHeader(lines[i])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		from, to := 0, 0
+		for from < hunk.fromCount || to < hunk.toCount {
+			i++
+			if i >= len(lines) {
+				return nil, fmt.Errorf("line %d: hunk ends early", i)
+			}
+			line := lines[i]
+			if strings.HasPrefix(line, "\\") {
+				continue
+			}
+			if line == "" {
+				line = " " // some tools strip the space of empty context lines
+			}
+			switch line[0] {
+			case ' ':
+				from++
+				to++
+			case '-':
+				from++
+			case '+':
+				to++
+			default:
+				return nil, fmt.Errorf("line %d: unexpected %q in hunk", i+1, line)
+			}
+			hunk.lines = append(hunk.lines, diffLine{line[0], line[1:]})
+		}
+		if from != hunk.fromCount || to != hunk.toCount {
+			return nil, fmt.Errorf("line %d: hunk line counts do not match its header", i+1)
+		}
+		result = append(result, hunk)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("patch has no hunks")
+	}
+	return result, nil
+}
+
+// parseHunkHeader reads "@@ -a,b +c,d @@", a missing count means 1
+func parseHunkHeader(header string) (diffHunk, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return diffHunk{}, fmt.Errorf("invalid hunk header %q", header)
+	}
+	fromStart, fromCount, err := parseRange(fields[1][1:])
+	if err != nil {
+		return diffHunk{}, fmt.Errorf("invalid hunk header %q", header)
+	}
+	toStart, toCount, err := parseRange(fields[2][1:])
+	if err != nil {
+		return diffHunk{}, fmt.Errorf("invalid hunk header %q", header)
+	}
+	return diffHunk{fromStart: fromStart, fromCount: fromCount, toStart: toStart, toCount: toCount}, nil
+}
+
+func parseRange(text string) (int, int, error) {
+	start, count, found := strings.Cut(text, ",")
+	from, err := strconv.Atoi(start)
+	if err != nil || from < 0 {
+		return 0, 0, errors.New("invalid range")
+	}
+	if !found {
+		return from, 1, nil
+	}
+	lines, err := strconv.Atoi(count)
+	if err != nil || lines < 0 {
+		return 0, 0, errors.New("invalid range")
+	}
+	return from, lines, nil
+}
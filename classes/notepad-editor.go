@@ -0,0 +1,265 @@
+package classes
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NotePadEditor runs the ed-like editor on stdin and stdout, with an optional file to edit.
+// Edits to a file are journaled to a swap file, which is recovered from if a previous run crashed.
+func NotePadEditor(args []string) {
+	n := NewNotepad("")
+	if len(args) > 0 {
+		opened, err := Open(args[0])
+		if HasJournal(args[0]) {
+			opened, err = Recover(args[0])
+			if err == nil {
+				fmt.Println("recovered unsaved changes from the swap file")
+			}
+		}
+		switch {
+		case err == nil:
+			n = opened
+		case errors.Is(err, fs.ErrNotExist):
+			n.file.path = args[0]
+		default:
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := n.EnableJournal(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	// running out of input with unsaved changes leaves the swap file for next time
+	if RunNotepadEditor(n, os.Stdin, os.Stdout) || !n.IsDirty() {
+		n.DisableJournal()
+	}
+}
+
+// RunNotepadEditor reads one ed style command per line until q or the end of input:
+//
+//	p, 1,2p    print everything or a range ("," "$" and marks like 'a work as in ed)
+//	3i text    append text to line 3
+//	2d, 1,3d   delete a line or a range
+//	1,3y [a]   copy a range, optionally into register a-z (A-Z appends)
+//	4x [a|2]   paste the last copy, a register or the 2nd most recent copy before line 4,
+//	           $+1x pastes after the last line
+//	3ka        set mark a on line 3, 'a can then be used as an address
+//	u, r       undo and redo
+//	w [file]   save, or save to a new file
+//	q          quit, asking again if there are unsaved changes
+//
+// Problems are reported as "? message" and the editor carries on, so a command file can be piped in.
+// It reports whether the session ended with q rather than at the end of input.
+func RunNotepadEditor(n *Notepad, in io.Reader, out io.Writer) bool {
+	e := &lineEditor{n: n, out: out}
+	scanner := bufio.NewScanner(in)
+	var journalErr, registersErr error
+	for scanner.Scan() {
+		quit, err := e.execute(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(out, "? %s\n", err)
+		}
+		if err := n.JournalErr(); err != nil && err != journalErr {
+			fmt.Fprintf(out, "? swap file: %s, w to try again\n", err)
+		}
+		journalErr = n.JournalErr()
+		if err := n.RegistersErr(); err != nil && err != registersErr {
+			fmt.Fprintf(out, "? registers: %s, the file was saved without them\n", err)
+		}
+		registersErr = n.RegistersErr()
+		if quit {
+			return true
+		}
+	}
+	return false
+}
+
+type lineEditor struct {
+	n   *Notepad
+	out io.Writer
+	// set after q was refused because of unsaved changes, a second q quits anyway
+	warned bool
+}
+
+func (e *lineEditor) execute(command string) (bool, error) {
+	start, end, rest, err := e.parseAddresses(strings.TrimSpace(command))
+	if err != nil {
+		return false, err
+	}
+	if rest == "" {
+		return false, errors.New("missing command")
+	}
+	name, arg := rest[0], strings.TrimPrefix(rest[1:], " ")
+	if name != 'q' {
+		e.warned = false
+	}
+
+	lines := e.n.allContent.Len()
+	// one past the last line is only an address for x, which pastes before it
+	if end > lines && name != 'x' {
+		return false, fmt.Errorf("invalid address %d", end)
+	}
+	switch name {
+	case 'p':
+		if start == 0 {
+			start, end = 1, lines
+		}
+		for _, line := range e.n.allContent.Lines(start-1, end) {
+			fmt.Fprintln(e.out, line)
+		}
+
+	case 'i':
+		if start == 0 || start != end {
+			return false, errors.New("i needs a single line address")
+		}
+		return false, e.n.insert(start, arg)
+
+	case 'd':
+		if start == 0 {
+			return false, errors.New("d needs an address")
+		}
+		return false, e.n.deleteRange(start, end)
+
+	case 'y':
+		if start == 0 {
+			return false, errors.New("y needs an address")
+		}
+		register, err := parseRegister(arg)
+		if err != nil {
+			return false, err
+		}
+		return false, e.n.copyRangeTo(start, end, register)
+
+	case 'x':
+		if start == 0 || start != end {
+			return false, errors.New("x needs a single line address")
+		}
+		if index, err := strconv.Atoi(arg); err == nil {
+			return false, e.n.pasteRecent(start, index)
+		}
+		register, err := parseRegister(arg)
+		if err != nil {
+			return false, err
+		}
+		return false, e.n.pasteFrom(start, register)
+
+	case 'k':
+		if start == 0 || start != end {
+			return false, errors.New("k needs a single line address")
+		}
+		name, size := utf8.DecodeRuneInString(arg)
+		if size != len(arg) {
+			return false, fmt.Errorf("%w %q", ErrInvalidMark, arg)
+		}
+		return false, e.n.SetMark(name, start)
+
+	case 'u':
+		return false, e.n.undo()
+
+	case 'r':
+		return false, e.n.redo()
+
+	case 'w':
+		var err error
+		if arg != "" {
+			err = e.n.SaveAs(arg)
+		} else {
+			err = e.n.Save()
+		}
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(e.out, "%d lines written to %s\n", e.n.allContent.Len(), e.n.Path())
+
+	case 'q':
+		if e.n.IsDirty() && !e.warned {
+			e.warned = true
+			return false, errors.New("unsaved changes, q again to quit")
+		}
+		return true, nil
+
+	default:
+		return false, fmt.Errorf("unknown command %q", name)
+	}
+	return false, nil
+}
+
+// parseAddresses reads an optional "a" or "a,b" prefix, 0 means no address was given
+func (e *lineEditor) parseAddresses(command string) (int, int, string, error) {
+	lines := e.n.allContent.Len()
+	if strings.HasPrefix(command, ",") {
+		return 1, lines, command[1:], nil
+	}
+
+	start, rest, err := e.parseAddress(command)
+	if err != nil || start == 0 {
+		return 0, 0, rest, err
+	}
+	end := start
+	if strings.HasPrefix(rest, ",") {
+		end, rest, err = e.parseAddress(rest[1:])
+		if err != nil {
+			return 0, 0, rest, err
+		}
+		if end == 0 {
+			return 0, 0, rest, errors.New("missing second address")
+		}
+	}
+	if start > end {
+		return 0, 0, rest, fmt.Errorf("invalid range %d,%d", start, end)
+	}
+	return start, end, rest, nil
+}
+
+// parseAddress reads a line number, $, $+1 or a mark. Addresses run up to one past the last line
+// so that x can paste at the end, execute refuses that one for the other commands.
+func (e *lineEditor) parseAddress(command string) (int, string, error) {
+	lines := e.n.allContent.Len()
+	if strings.HasPrefix(command, "$+1") {
+		return lines + 1, command[3:], nil
+	}
+	if strings.HasPrefix(command, "$") {
+		if lines == 0 {
+			return 0, command, errors.New("invalid address $, the document is empty")
+		}
+		return lines, command[1:], nil
+	}
+	if strings.HasPrefix(command, "'") {
+		name, size := utf8.DecodeRuneInString(command[1:])
+		line, err := e.n.Mark(name)
+		return line, command[1+size:], err
+	}
+
+	digits := 0
+	for digits < len(command) && command[digits] >= '0' && command[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 {
+		return 0, command, nil
+	}
+	line, _ := strconv.Atoi(command[:digits])
+	if line < 1 || line > lines+1 {
+		return 0, command, fmt.Errorf("invalid address %d", line)
+	}
+	return line, command[digits:], nil
+}
+
+// parseRegister reads an optional single letter register name, 0 stands for the unnamed register
+func parseRegister(arg string) (rune, error) {
+	if arg == "" {
+		return 0, nil
+	}
+	runes := []rune(arg)
+	if len(runes) != 1 || !isRegister(runes[0]) {
+		return 0, fmt.Errorf("%w %q", ErrInvalidRegister, arg)
+	}
+	return runes[0], nil
+}
@@ -0,0 +1,135 @@
+package classes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEditorGolden pipes command scripts through the editor and compares everything it prints,
+// "? message" lines included, with testdata/editor-<name>.golden. $DIR in a script is a temp directory.
+func TestEditorGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		script string
+	}{
+		{
+			name: "print",
+			text: "one\ntwo\nthree",
+			script: `p
+2p
+2,$p
+,p
+$p`,
+		},
+		{
+			name: "edit-undo-redo",
+			text: "one\ntwo\nthree",
+			script: `1i  more
+2d
+p
+u
+p
+u
+u
+r
+p`,
+		},
+		{
+			name: "yank-and-paste",
+			text: "one\ntwo\nthree",
+			script: `1,2y
+3x
+p
+$y a
+1x a
+p
+$+1x a
+p
+,y
+4x 2
+p`,
+		},
+		{
+			name: "paste-into-emptied-document",
+			text: "one\ntwo",
+			script: `,y
+,d
+p
+1x
+p
+,d
+$+1x
+p`,
+		},
+		{
+			name: "marks",
+			text: "one\ntwo\nthree\nfour",
+			script: `3ka
+1d
+'ap
+'a,$p
+'ad
+'ap
+u
+'ap
+'bp`,
+		},
+		{
+			name: "errors",
+			text: "one\ntwo",
+			script: `
+z
+5p
+3p
+2,1p
+3d
+1,p
+i text
+2,3x
+1y !
+1x b
+u
+r
+1k1
+'zp
+1i !
+q
+p
+q
+q
+p`,
+		},
+		{
+			name: "write",
+			text: "one\ntwo",
+			script: `1i !
+w
+w $DIR/out.txt
+q`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var out strings.Builder
+			script := strings.ReplaceAll(tt.script, "$DIR", dir)
+			RunNotepadEditor(NewNotepad(tt.text), strings.NewReader(script+"\n"), &out)
+			checkGolden(t, "editor-"+tt.name, strings.ReplaceAll(out.String(), dir, "$DIR"))
+
+			if tt.name == "write" {
+				written, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+				if err != nil || string(written) != "one!\ntwo" {
+					t.Fatalf("written file %q, %v", written, err)
+				}
+				// nothing was copied, so no registers file is left next to the document
+				if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+					t.Fatalf("%d files written, want only out.txt", len(entries))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errors returned by Notepad operations, wrapped with the values that were wrong so errors.Is works
+var (
+	ErrLineOutOfRange   = errors.New("line out of range")
+	ErrColumnOutOfRange = errors.New("column out of range")
+	ErrEmptyRange       = errors.New("range ends before it starts")
+	ErrEmptyClipboard   = errors.New("clipboard is empty")
+	ErrInvalidRegister  = errors.New("invalid register")
+	ErrNothingToUndo    = errors.New("nothing to undo")
+	ErrNothingToRedo    = errors.New("nothing to redo")
+	ErrEmptyPattern     = errors.New("empty search pattern")
+	ErrInvalidPattern   = errors.New("invalid search pattern")
+)
+
+// checkLine accepts an existing line
+func (n *Notepad) checkLine(line int) error {
+	if line < 1 || line > n.allContent.Len() {
+		return fmt.Errorf("%w: line %d of %d", ErrLineOutOfRange, line, n.allContent.Len())
+	}
+	return nil
+}
+
+// checkInsertLine accepts an existing line or one past the last, to insert before it
+func (n *Notepad) checkInsertLine(line int) error {
+	if line < 1 || line > n.allContent.Len()+1 {
+		return fmt.Errorf("%w: cannot insert before line %d of %d", ErrLineOutOfRange, line, n.allContent.Len())
+	}
+	return nil
+}
+
+// checkRange accepts start to end inclusive, both existing lines
+func (n *Notepad) checkRange(start, end int) error {
+	if err := n.checkLine(start); err != nil {
+		return err
+	}
+	if err := n.checkLine(end); err != nil {
+		return err
+	}
+	if start > end {
+		return fmt.Errorf("%w: %d,%d", ErrEmptyRange, start, end)
+	}
+	return nil
+}
+
+// fail returns err for an invalid edit and marks the open transaction, if any, so it cannot be committed
+func (n *Notepad) fail(err error) error {
+	if n.tx != nil {
+		n.tx.failed = true
+	}
+	return err
+}
@@ -0,0 +1,166 @@
+package classes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrNoPath      = errors.New("notepad has no file path, use SaveAs")
+	ErrFileChanged = errors.New("file changed on disk since it was opened")
+)
+
+// notepadFile remembers where a document came from and how it was laid out on disk
+type notepadFile struct {
+	path            string
+	crlf            bool
+	trailingNewline bool
+	// hash of the bytes on disk when the file was last read or written, nil if it never was
+	diskHash []byte
+}
+
+// Open reads a file keeping its line ending style and whether it ends with a newline,
+// registers saved alongside the file are restored too
+func Open(path string) (*Notepad, error) {
+	lines, file, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	n := NewNotepadWithStorage(NewRopeStorage(lines))
+	n.file = file
+	if err := n.clipboard.load(path); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func readDocument(path string) ([]string, notepadFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, notepadFile{}, err
+	}
+
+	text := string(data)
+	crlf := strings.Count(text, "\r\n") > strings.Count(text, "\n")/2
+	newline := "\n"
+	if crlf {
+		newline = "\r\n"
+	}
+	trailing := strings.HasSuffix(text, newline)
+	text = strings.TrimSuffix(text, newline)
+
+	hash := sha256.Sum256(data)
+	return strings.Split(text, newline), notepadFile{path: path, crlf: crlf, trailingNewline: trailing, diskHash: hash[:]}, nil
+}
+
+func (n *Notepad) Path() string {
+	return n.file.path
+}
+
+// IsDirty reports whether the content differs from what was last opened or saved
+func (n *Notepad) IsDirty() bool {
+	return n.history.current != n.savedNode
+}
+
+// Save writes the document back to its file, refusing if someone else changed the file in the meantime
+// or while a transaction is open
+func (n *Notepad) Save() error {
+	return n.save(n.file.path, false)
+}
+
+// ForceSave writes the document back to its file even if it changed on disk
+func (n *Notepad) ForceSave() error {
+	return n.save(n.file.path, true)
+}
+
+// SaveAs writes the document to a new path, which becomes the path used by Save
+func (n *Notepad) SaveAs(path string) error {
+	return n.save(path, true)
+}
+
+func (n *Notepad) save(path string, force bool) error {
+	// the file would get edits that a Rollback can still take back
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	if path == "" {
+		return ErrNoPath
+	}
+	if !force && n.file.diskHash != nil {
+		current, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		hash := sha256.Sum256(current)
+		if err != nil || !bytes.Equal(hash[:], n.file.diskHash) {
+			return fmt.Errorf("%s: %w", path, ErrFileChanged)
+		}
+	}
+
+	newline := "\n"
+	if n.file.crlf {
+		newline = "\r\n"
+	}
+	text := strings.Join(n.lines(), newline)
+	if n.file.trailingNewline {
+		text += newline
+	}
+	data := []byte(text)
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(data)
+	n.file.path = path
+	n.file.diskHash = hash[:]
+	n.savedNode = n.history.current
+	if n.journal != nil {
+		if err := n.compactJournal(); err != nil {
+			return err
+		}
+	}
+	// the document is saved by now, a registers file that cannot be written does not undo that
+	n.registersErr = nil
+	if !n.sharedClipboard {
+		n.registersErr = n.clipboard.save(path)
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temp file in the same directory and renames it over path,
+// so readers only ever see the old or the new content
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once the rename went through
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,243 @@
+package classes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// undoNode is one state of the document, op turns the parent's content into this node's content.
+// The root is the oldest state still remembered and has no op.
+type undoNode struct {
+	seq      int
+	at       time.Time
+	op       editOp
+	parent   *undoNode
+	children []*undoNode // oldest first
+	// child that redo moves to, the branch last undone from or else the newest one
+	redoChild *undoNode
+}
+
+// undoHistory is a tree of document states. By default it behaves like plain undo/redo stacks:
+// a new edit after an undo throws the undone branch away. With branching on (like vim's undo tree)
+// abandoned branches are kept and can be reached again by sequence number or time.
+type undoHistory struct {
+	root      *undoNode
+	current   *undoNode
+	nodes     map[int]*undoNode
+	nextSeq   int
+	branching bool
+	// 0 means unlimited, otherwise the oldest states are forgotten once either limit is passed
+	maxSteps int
+	maxBytes int
+	bytes    int
+}
+
+func newUndoHistory() *undoHistory {
+	root := &undoNode{seq: 0, at: time.Now()}
+	return &undoHistory{root: root, current: root, nodes: map[int]*undoNode{0: root}, nextSeq: 1}
+}
+
+// UndoState describes a remembered state of the document
+type UndoState struct {
+	Seq     int
+	Time    time.Time
+	Current bool
+}
+
+func (h *undoHistory) record(op editOp) {
+	if !h.branching {
+		for _, child := range h.current.children {
+			h.forget(child)
+		}
+		h.current.children = nil
+		h.current.redoChild = nil
+	}
+
+	node := &undoNode{seq: h.nextSeq, at: time.Now(), op: op, parent: h.current}
+	h.nextSeq++
+	h.current.children = append(h.current.children, node)
+	h.current.redoChild = node
+	h.nodes[node.seq] = node
+	h.bytes += op.size()
+	h.current = node
+	h.trim()
+}
+
+func (h *undoHistory) undo(n *Notepad) {
+	node := h.current
+	node.op.revert(n)
+	node.parent.redoChild = node
+	h.current = node.parent
+}
+
+func (h *undoHistory) redoTarget() *undoNode {
+	if h.current.redoChild != nil {
+		return h.current.redoChild
+	}
+	if len(h.current.children) > 0 {
+		return h.current.children[len(h.current.children)-1]
+	}
+	return nil
+}
+
+func (h *undoHistory) redo(n *Notepad) {
+	node := h.redoTarget()
+	node.op.apply(n)
+	h.current = node
+}
+
+// moveTo walks from the current state to target, undoing up to their common ancestor and redoing down from it
+func (h *undoHistory) moveTo(n *Notepad, target *undoNode) {
+	depth := func(node *undoNode) int {
+		d := 0
+		for ; node.parent != nil; node = node.parent {
+			d++
+		}
+		return d
+	}
+
+	var down []*undoNode
+	from, to := h.current, target
+	fromDepth, toDepth := depth(from), depth(to)
+	for fromDepth > toDepth {
+		h.undo(n)
+		from = h.current
+		fromDepth--
+	}
+	for toDepth > fromDepth {
+		down = append(down, to)
+		to = to.parent
+		toDepth--
+	}
+	for from != to {
+		h.undo(n)
+		from = h.current
+		down = append(down, to)
+		to = to.parent
+	}
+	for i := len(down) - 1; i >= 0; i-- {
+		down[i].op.apply(n)
+		down[i].parent.redoChild = down[i]
+		h.current = down[i]
+	}
+}
+
+// trim forgets the oldest states until the history fits its limits, abandoned branches
+// hanging off the root go first and then the root moves towards the current state
+func (h *undoHistory) trim() {
+	for (h.maxSteps > 0 && len(h.nodes)-1 > h.maxSteps) || (h.maxBytes > 0 && h.bytes > h.maxBytes) {
+		onPath := h.current
+		for onPath != h.root && onPath.parent != h.root {
+			onPath = onPath.parent
+		}
+
+		abandoned := -1
+		for i, child := range h.root.children {
+			if child != onPath {
+				abandoned = i
+				break
+			}
+		}
+		if abandoned != -1 {
+			child := h.root.children[abandoned]
+			h.forget(child)
+			h.root.children = append(h.root.children[:abandoned:abandoned], h.root.children[abandoned+1:]...)
+			if h.root.redoChild == child {
+				h.root.redoChild = nil
+			}
+			continue
+		}
+		if onPath == h.root {
+			return
+		}
+
+		delete(h.nodes, h.root.seq)
+		h.bytes -= onPath.op.size()
+		onPath.op = nil
+		onPath.parent = nil
+		h.root = onPath
+	}
+}
+
+// forget drops a node and everything below it
+func (h *undoHistory) forget(node *undoNode) {
+	for _, child := range node.children {
+		h.forget(child)
+	}
+	delete(h.nodes, node.seq)
+	h.bytes -= node.op.size()
+}
+
+func (h *undoHistory) states() []UndoState {
+	states := make([]UndoState, 0, len(h.nodes))
+	for _, node := range h.nodes {
+		states = append(states, UndoState{Seq: node.seq, Time: node.at, Current: node == h.current})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Seq < states[j].Seq })
+	return states
+}
+
+// SetHistoryLimit bounds the undo history by number of steps and by the bytes the steps hold, 0 disables a limit
+func (n *Notepad) SetHistoryLimit(maxSteps, maxBytes int) {
+	n.logJournal(journalEntry{Limits: []int{maxSteps, maxBytes}})
+	n.history.maxSteps = maxSteps
+	n.history.maxBytes = maxBytes
+	n.history.trim()
+}
+
+// SetUndoTree turns branching history on or off, branches kept so far stay reachable through UndoTo
+func (n *Notepad) SetUndoTree(enabled bool) {
+	n.logJournal(journalEntry{Branching: &enabled})
+	n.history.branching = enabled
+}
+
+// UndoBranches lists the tip of every branch in the undo tree, oldest first
+func (n *Notepad) UndoBranches() []UndoState {
+	var tips []UndoState
+	for _, state := range n.history.states() {
+		if len(n.history.nodes[state.Seq].children) == 0 {
+			tips = append(tips, state)
+		}
+	}
+	return tips
+}
+
+// UndoStates lists every remembered state in the order they were created
+func (n *Notepad) UndoStates() []UndoState {
+	return n.history.states()
+}
+
+func (n *Notepad) CurrentSeq() int {
+	return n.history.current.seq
+}
+
+// UndoTo jumps to the state with the given sequence number, on any branch
+func (n *Notepad) UndoTo(seq int) error {
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	target, ok := n.history.nodes[seq]
+	if !ok {
+		return fmt.Errorf("%w: no undo state %d", ErrNothingToUndo, seq)
+	}
+	n.logJournal(journalEntry{To: &seq})
+	n.history.moveTo(n, target)
+	return nil
+}
+
+// UndoToTime jumps to the newest state that already existed at t, or the oldest one kept if t is earlier
+func (n *Notepad) UndoToTime(t time.Time) error {
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	target := n.history.root
+	for _, node := range n.history.nodes {
+		if !node.at.After(t) && node.seq > target.seq {
+			target = node
+		}
+	}
+	n.logJournal(journalEntry{To: &target.seq})
+	n.history.moveTo(n, target)
+	return nil
+}
@@ -0,0 +1,97 @@
+package classes
+
+import (
+	"fmt"
+	"testing"
+)
+
+const benchDocumentLines = 100_000
+
+// editsPerRound edits are made and then undone in every benchmark iteration, so the history
+// never holds more than that many steps
+const editsPerRound = 10
+
+func benchDocument() []string {
+	lines := make([]string, benchDocumentLines)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d of the benchmark document", i+1)
+	}
+	return lines
+}
+
+// snapshotNotepad is the undo design Notepad had before edit operations: every edit pushes a deep
+// copy of the whole document, kept here so the two designs can be measured side by side
+type snapshotNotepad struct {
+	allContent []string
+	undoStack  [][]string
+	redoStack  [][]string
+}
+
+func (n *snapshotNotepad) insert(line int, text string) {
+	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
+	n.allContent[line-1] += text
+}
+
+func (n *snapshotNotepad) deleteLine(line int) {
+	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
+	n.allContent = append(n.allContent[:line-1], n.allContent[line:]...)
+}
+
+func (n *snapshotNotepad) undo() {
+	n.redoStack = append(n.redoStack, n.allContent)
+	n.allContent = n.undoStack[len(n.undoStack)-1]
+	n.undoStack = n.undoStack[:len(n.undoStack)-1]
+}
+
+// history-B is what the undo history holds after a round of edits, counted with linesSize for both designs
+func BenchmarkUndoSnapshots(b *testing.B) {
+	n := &snapshotNotepad{allContent: benchDocument()}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	history := 0
+	for i := 0; i < b.N; i++ {
+		for edit := 0; edit < editsPerRound; edit++ {
+			line := 1 + (i*editsPerRound+edit)%(len(n.allContent)-1)
+			if edit%2 == 0 {
+				n.insert(line, " edited")
+			} else {
+				n.deleteLine(line)
+			}
+		}
+		history = 0
+		for _, snapshot := range n.undoStack {
+			history += linesSize(snapshot)
+		}
+		for edit := 0; edit < editsPerRound; edit++ {
+			n.undo()
+		}
+		n.redoStack = nil
+	}
+	b.ReportMetric(float64(history), "history-B")
+}
+
+// BenchmarkUndoOps uses the default rope storage, a SliceStorage would copy the document on every
+// splice whatever the undo design
+func BenchmarkUndoOps(b *testing.B) {
+	n := NewNotepadWithStorage(NewRopeStorage(benchDocument()))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	history := 0
+	for i := 0; i < b.N; i++ {
+		for edit := 0; edit < editsPerRound; edit++ {
+			line := 1 + (i*editsPerRound+edit)%(n.allContent.Len()-1)
+			if edit%2 == 0 {
+				n.insert(line, " edited")
+			} else {
+				n.deleteLine(line)
+			}
+		}
+		history = n.history.bytes
+		for edit := 0; edit < editsPerRound; edit++ {
+			n.undo()
+		}
+	}
+	b.ReportMetric(float64(history), "history-B")
+}
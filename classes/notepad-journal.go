@@ -0,0 +1,402 @@
+package classes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrJournalMismatch = errors.New("journal was written for a different version of the file")
+	ErrCorruptJournal  = errors.New("journal is corrupt")
+)
+
+// notepadJournal is a write-ahead swap file next to the document. It starts with a snapshot of the
+// undo history relative to the file on disk, then gets one line per edit, undo or redo, each synced
+// before the change is made. Saving compacts it back to a single snapshot.
+type notepadJournal struct {
+	path string
+	file *os.File
+	// the write that failed, nothing more is logged until compacting starts a fresh file
+	err error
+}
+
+// journalPath is the hidden swap file next to a document
+func journalPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".swp")
+}
+
+type journalOp struct {
+	Kind     string          `json:"kind"`
+	Line     int             `json:"line,omitempty"`
+	Text     string          `json:"text,omitempty"`
+	Removed  []string        `json:"removed,omitempty"`
+	Inserted []string        `json:"inserted,omitempty"`
+	Changes  []journalChange `json:"changes,omitempty"`
+	Ops      []journalOp     `json:"ops,omitempty"`
+}
+
+type journalChange struct {
+	Line   int    `json:"line"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type journalNode struct {
+	Seq       int        `json:"seq"`
+	Parent    int        `json:"parent"`
+	At        time.Time  `json:"at"`
+	Op        *journalOp `json:"op,omitempty"`
+	RedoChild int        `json:"redoChild,omitempty"`
+}
+
+// journalSnapshot is the whole undo tree. The base state's content is the file with FileHash,
+// or Lines when the history no longer reaches the state that was saved.
+type journalSnapshot struct {
+	FileHash  []byte        `json:"fileHash,omitempty"`
+	Lines     []string      `json:"lines"`
+	Nodes     []journalNode `json:"nodes"`
+	Base      int           `json:"base"`
+	Current   int           `json:"current"`
+	NextSeq   int           `json:"nextSeq"`
+	Branching bool          `json:"branching"`
+	MaxSteps  int           `json:"maxSteps"`
+	MaxBytes  int           `json:"maxBytes"`
+}
+
+type journalEdit struct {
+	At time.Time `json:"at"`
+	Op journalOp `json:"op"`
+}
+
+// journalEntry is one line of the journal, exactly one field is set
+type journalEntry struct {
+	Snapshot  *journalSnapshot `json:"snapshot,omitempty"`
+	Do        *journalEdit     `json:"do,omitempty"`
+	Undo      bool             `json:"undo,omitempty"`
+	Redo      bool             `json:"redo,omitempty"`
+	To        *int             `json:"to,omitempty"`
+	Branching *bool            `json:"branching,omitempty"`
+	Limits    []int            `json:"limits,omitempty"`
+}
+
+func encodeOp(op editOp) journalOp {
+	switch op := op.(type) {
+	case *appendTextOp:
+		return journalOp{Kind: "append", Line: op.line, Text: op.text}
+	case *spliceOp:
+		return journalOp{Kind: "splice", Line: op.line, Removed: op.removed, Inserted: op.inserted}
+	case *replaceLinesOp:
+		encoded := journalOp{Kind: "replace"}
+		for _, change := range op.changes {
+			encoded.Changes = append(encoded.Changes, journalChange{change.line, change.before, change.after})
+		}
+		return encoded
+	case *groupOp:
+		encoded := journalOp{Kind: "group"}
+		for _, inner := range op.ops {
+			encoded.Ops = append(encoded.Ops, encodeOp(inner))
+		}
+		return encoded
+	}
+	panic(fmt.Sprintf("journal: unknown edit %T", op))
+}
+
+func decodeOp(encoded journalOp) (editOp, error) {
+	switch encoded.Kind {
+	case "append":
+		return &appendTextOp{line: encoded.Line, text: encoded.Text}, nil
+	case "splice":
+		return &spliceOp{line: encoded.Line, removed: encoded.Removed, inserted: encoded.Inserted}, nil
+	case "replace":
+		op := &replaceLinesOp{}
+		for _, change := range encoded.Changes {
+			op.changes = append(op.changes, lineChange{change.Line, change.Before, change.After})
+		}
+		return op, nil
+	case "group":
+		op := &groupOp{}
+		for _, inner := range encoded.Ops {
+			decoded, err := decodeOp(inner)
+			if err != nil {
+				return nil, err
+			}
+			op.ops = append(op.ops, decoded)
+		}
+		return op, nil
+	}
+	return nil, fmt.Errorf("%w: unknown edit %q", ErrCorruptJournal, encoded.Kind)
+}
+
+// EnableJournal starts keeping a swap file next to the document so unsaved edits survive a crash,
+// the document needs a path first
+func (n *Notepad) EnableJournal() error {
+	if n.file.path == "" {
+		return ErrNoPath
+	}
+	if n.journal != nil {
+		return nil
+	}
+	n.journal = &notepadJournal{}
+	if err := n.compactJournal(); err != nil {
+		n.journal = nil
+		return err
+	}
+	return nil
+}
+
+// DisableJournal stops journaling and removes the swap file, call it when a session ends normally
+func (n *Notepad) DisableJournal() error {
+	if n.journal == nil {
+		return nil
+	}
+	j := n.journal
+	n.journal = nil
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(j.path)
+}
+
+// HasJournal reports whether a swap file was left behind for path, i.e. whether Recover has work to do
+func HasJournal(path string) bool {
+	_, err := os.Stat(journalPath(path))
+	return err == nil
+}
+
+// compactJournal replaces the journal with a snapshot of the current history, it follows the
+// document to a new path after SaveAs. It is refused during a transaction, the snapshot would
+// already hold the edits that Commit logs again.
+func (n *Notepad) compactJournal() error {
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	snapshot := n.snapshotHistory()
+	data, err := json.Marshal(journalEntry{Snapshot: &snapshot})
+	if err != nil {
+		return err
+	}
+
+	path := journalPath(n.file.path)
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+
+	old := n.journal
+	if old.file != nil {
+		old.file.Close()
+		if old.path != path {
+			os.Remove(old.path)
+		}
+	}
+	n.journal = &notepadJournal{path: path, file: file}
+	return nil
+}
+
+func (n *Notepad) snapshotHistory() journalSnapshot {
+	h := n.history
+	snapshot := journalSnapshot{
+		FileHash:  n.file.diskHash,
+		Current:   h.current.seq,
+		NextSeq:   h.nextSeq,
+		Branching: h.branching,
+		MaxSteps:  h.maxSteps,
+		MaxBytes:  h.maxBytes,
+	}
+	// a file that was never saved has no content on disk to start from, so its lines are kept too
+	if n.file.diskHash != nil && n.savedNode != nil && h.nodes[n.savedNode.seq] == n.savedNode {
+		snapshot.Base = n.savedNode.seq
+	} else {
+		snapshot.Base = h.current.seq
+		snapshot.Lines = n.lines()
+	}
+
+	for _, state := range h.states() {
+		node := h.nodes[state.Seq]
+		encoded := journalNode{Seq: node.seq, Parent: -1, At: node.at}
+		if node.parent != nil {
+			encoded.Parent = node.parent.seq
+			op := encodeOp(node.op)
+			encoded.Op = &op
+		}
+		if node.redoChild != nil {
+			encoded.RedoChild = node.redoChild.seq
+		}
+		snapshot.Nodes = append(snapshot.Nodes, encoded)
+	}
+	return snapshot
+}
+
+// logJournal writes an entry ahead of the change it describes. A failing journal does not stop
+// the edit, the error is kept for JournalErr and journaling pauses until the next save.
+func (n *Notepad) logJournal(entry journalEntry) {
+	if n.journal == nil || n.journal.err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = n.journal.file.Write(append(data, '\n'))
+	}
+	if err == nil {
+		err = n.journal.file.Sync()
+	}
+	n.journal.err = err
+}
+
+// JournalErr returns why the swap file stopped keeping up with the edits, nil while it works.
+// Saving writes a fresh swap file and clears it.
+func (n *Notepad) JournalErr() error {
+	if n.journal == nil {
+		return nil
+	}
+	return n.journal.err
+}
+
+// Recover opens path and replays the swap file left next to it, bringing back unsaved edits and
+// the undo history. The recovered notepad keeps journaling.
+func Recover(path string) (*Notepad, error) {
+	data, err := os.ReadFile(journalPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	// a crash in the middle of a write leaves a torn last line, that change never happened
+	lines := bytes.Split(data, []byte("\n"))
+	lines = lines[:len(lines)-1]
+	var entries []journalEntry
+	for i, line := range lines {
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrCorruptJournal, i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 || entries[0].Snapshot == nil {
+		return nil, fmt.Errorf("%w: it does not start with a snapshot", ErrCorruptJournal)
+	}
+
+	n, err := restoreSnapshot(path, entries[0].Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries[1:] {
+		if err := n.replay(entry); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrCorruptJournal, i+2, err)
+		}
+	}
+
+	if err := n.EnableJournal(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func restoreSnapshot(path string, snapshot *journalSnapshot) (*Notepad, error) {
+	var n *Notepad
+	opened, err := Open(path)
+	switch {
+	case err == nil:
+		n = opened
+	case errors.Is(err, fs.ErrNotExist) && snapshot.Lines != nil:
+		n = NewNotepad("")
+		n.file.path = path
+	default:
+		return nil, err
+	}
+
+	if snapshot.Lines != nil {
+		n.allContent = NewRopeStorage(snapshot.Lines)
+	} else if !bytes.Equal(n.file.diskHash, snapshot.FileHash) {
+		return nil, fmt.Errorf("%s: %w", path, ErrJournalMismatch)
+	}
+
+	h := &undoHistory{
+		nodes:     make(map[int]*undoNode),
+		nextSeq:   snapshot.NextSeq,
+		branching: snapshot.Branching,
+		maxSteps:  snapshot.MaxSteps,
+		maxBytes:  snapshot.MaxBytes,
+	}
+	for _, encoded := range snapshot.Nodes {
+		node := &undoNode{seq: encoded.Seq, at: encoded.At}
+		if encoded.Parent == -1 {
+			h.root = node
+		} else {
+			parent, ok := h.nodes[encoded.Parent]
+			if !ok {
+				return nil, fmt.Errorf("%w: state %d comes before its parent %d", ErrCorruptJournal, encoded.Seq, encoded.Parent)
+			}
+			if encoded.Op == nil {
+				return nil, fmt.Errorf("%w: state %d has no edit", ErrCorruptJournal, encoded.Seq)
+			}
+			op, err := decodeOp(*encoded.Op)
+			if err != nil {
+				return nil, err
+			}
+			node.op, node.parent = op, parent
+			parent.children = append(parent.children, node)
+			h.bytes += op.size()
+		}
+		h.nodes[node.seq] = node
+	}
+	base, ok := h.nodes[snapshot.Base]
+	current, ok2 := h.nodes[snapshot.Current]
+	if h.root == nil || !ok || !ok2 {
+		return nil, fmt.Errorf("%w: snapshot is missing states", ErrCorruptJournal)
+	}
+
+	n.history = h
+	h.current = base
+	if snapshot.Lines == nil {
+		n.savedNode = base
+	} else {
+		n.savedNode = nil
+	}
+	h.moveTo(n, current)
+	for _, encoded := range snapshot.Nodes {
+		if encoded.RedoChild != 0 {
+			h.nodes[encoded.Seq].redoChild = h.nodes[encoded.RedoChild]
+		}
+	}
+	return n, nil
+}
+
+func (n *Notepad) replay(entry journalEntry) error {
+	switch {
+	case entry.Do != nil:
+		op, err := decodeOp(entry.Do.Op)
+		if err != nil {
+			return err
+		}
+		n.do(op)
+		n.history.current.at = entry.Do.At
+	case entry.Undo:
+		if err := n.undo(); err != nil {
+			return err
+		}
+	case entry.Redo:
+		if err := n.redo(); err != nil {
+			return err
+		}
+	case entry.To != nil:
+		if err := n.UndoTo(*entry.To); err != nil {
+			return err
+		}
+	case entry.Branching != nil:
+		n.SetUndoTree(*entry.Branching)
+	case len(entry.Limits) == 2:
+		n.SetHistoryLimit(entry.Limits[0], entry.Limits[1])
+	default:
+		return fmt.Errorf("%w: empty entry", ErrCorruptJournal)
+	}
+	return nil
+}
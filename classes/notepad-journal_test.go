@@ -0,0 +1,79 @@
+package classes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestJournalTransaction saves and commits around a transaction and checks that recovering from
+// the swap file applies the transaction's edits exactly once
+func TestJournalTransaction(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit bool
+		want   []string
+	}{
+		{name: "committed", commit: true, want: []string{"aX", "b"}},
+		{name: "rolled back", commit: false, want: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "doc.txt")
+			if err := os.WriteFile(path, []byte("a\nb\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			n, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.EnableJournal(); err != nil {
+				t.Fatal(err)
+			}
+
+			n.Begin()
+			n.insert(1, "X")
+			if err := n.Save(); !errors.Is(err, ErrTransactionOpen) {
+				t.Fatalf("Save during a transaction returned %v, want ErrTransactionOpen", err)
+			}
+			if tt.commit {
+				err = n.Commit()
+			} else {
+				err = n.Rollback()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "a\nb\n" {
+				t.Fatalf("file on disk is %q, want it untouched", data)
+			}
+			if n.IsDirty() != tt.commit {
+				t.Fatalf("IsDirty() = %v, want %v", n.IsDirty(), tt.commit)
+			}
+
+			// the notepad is left as if the editor crashed here
+			recovered, err := Recover(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := recovered.lines(); !slices.Equal(got, tt.want) {
+				t.Fatalf("recovered %q, want %q", got, tt.want)
+			}
+
+			// once the transaction is closed the save goes through and the swap file starts over from it
+			if err := n.Save(); err != nil {
+				t.Fatal(err)
+			}
+			recovered, err = Recover(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := recovered.lines(); !slices.Equal(got, tt.want) {
+				t.Fatalf("recovered %q after saving, want %q", got, tt.want)
+			}
+		})
+	}
+}
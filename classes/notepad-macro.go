@@ -0,0 +1,193 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRecording     = errors.New("already recording a macro")
+	ErrNotRecording  = errors.New("not recording a macro")
+	ErrInvalidRepeat = errors.New("invalid repeat count")
+	ErrUnknownStep   = errors.New("unknown macro operation")
+)
+
+// MacroStep is one recorded operation, which of the other fields are used depends on Op
+type MacroStep struct {
+	Op        string   `json:"op"`
+	Line      int      `json:"line,omitempty"`
+	Column    int      `json:"column,omitempty"`
+	End       int      `json:"end,omitempty"`
+	EndColumn int      `json:"endColumn,omitempty"`
+	To        int      `json:"to,omitempty"`
+	Text      string   `json:"text,omitempty"`
+	Lines     []string `json:"lines,omitempty"`
+	Register  string   `json:"register,omitempty"`
+	Index     int      `json:"index,omitempty"`
+	// replaceAll
+	Pattern     string       `json:"pattern,omitempty"`
+	Replacement string       `json:"replacement,omitempty"`
+	Options     *FindOptions `json:"options,omitempty"`
+	// applyPatch
+	Patch string `json:"patch,omitempty"`
+	Fuzz  int    `json:"fuzz,omitempty"`
+}
+
+// Macro is a named sequence of edits that can be stored as JSON and played on any notepad.
+// Only edits that succeeded are recorded, undo and redo are not.
+type Macro struct {
+	Name  string      `json:"name"`
+	Steps []MacroStep `json:"steps"`
+}
+
+// MacroError tells which step of a playback failed and why, Repeat and Step count from 1
+type MacroError struct {
+	Macro  string
+	Repeat int
+	Step   int
+	Op     string
+	Err    error
+}
+
+func (e *MacroError) Error() string {
+	return fmt.Sprintf("macro %q stopped at step %d (%s) of repetition %d: %v", e.Macro, e.Step, e.Op, e.Repeat, e.Err)
+}
+
+func (e *MacroError) Unwrap() error {
+	return e.Err
+}
+
+// StartRecording captures the following edits into a macro until StopRecording
+func (n *Notepad) StartRecording(name string) error {
+	if n.recording != nil {
+		return ErrRecording
+	}
+	n.recording = &Macro{Name: name}
+	return nil
+}
+
+func (n *Notepad) StopRecording() (*Macro, error) {
+	if n.recording == nil {
+		return nil, ErrNotRecording
+	}
+	macro := n.recording
+	n.recording = nil
+	return macro, nil
+}
+
+func (n *Notepad) IsRecording() bool {
+	return n.recording != nil
+}
+
+func (n *Notepad) recordStep(step MacroStep) {
+	if n.recording == nil {
+		return
+	}
+	if step.Lines != nil {
+		step.Lines = append([]string{}, step.Lines...)
+	}
+	n.recording.Steps = append(n.recording.Steps, step)
+}
+
+// pauseRecording keeps the edits an operation makes through other operations out of the macro,
+// call the returned func to resume
+func (n *Notepad) pauseRecording() func() {
+	recording := n.recording
+	n.recording = nil
+	return func() {
+		n.recording = recording
+	}
+}
+
+// registerName stores a register in a macro, "" being the unnamed one
+func registerName(register rune) string {
+	if register == 0 {
+		return ""
+	}
+	return string(register)
+}
+
+func registerRune(name string) rune {
+	runes := []rune(name)
+	if len(runes) != 1 {
+		return 0
+	}
+	return runes[0]
+}
+
+// PlayMacro plays a macro repeat times, every repetition moving offset lines further down, so an
+// offset of 1 applies a one line edit to consecutive lines. The line range of a replaceAll moves
+// too, a patch is applied as recorded since its hunks are found by their context. Playback stops
+// at the first step that fails, what was applied until then stays and the whole playback is a
+// single undo step. It returns how many steps were applied.
+func (n *Notepad) PlayMacro(m *Macro, repeat, offset int) (int, error) {
+	if repeat < 1 {
+		return 0, fmt.Errorf("%w %d", ErrInvalidRepeat, repeat)
+	}
+	if err := n.Begin(); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for r := 0; r < repeat; r++ {
+		for i, step := range m.Steps {
+			if err := n.playStep(step, r*offset); err != nil {
+				// keep the steps that worked, only a failed step inside the transaction makes Commit roll back
+				n.tx.failed = false
+				n.Commit()
+				return applied, &MacroError{Macro: m.Name, Repeat: r + 1, Step: i + 1, Op: step.Op, Err: err}
+			}
+			applied++
+		}
+	}
+	return applied, n.Commit()
+}
+
+func (n *Notepad) playStep(step MacroStep, shift int) error {
+	line, end, to := step.Line+shift, step.End+shift, step.To+shift
+	switch step.Op {
+	case "insert":
+		return n.insert(line, step.Text)
+	case "insertAt":
+		return n.insertAt(line, step.Column, step.Text)
+	case "insertLines":
+		return n.insertLines(line, step.Lines...)
+	case "deleteLine":
+		return n.deleteLine(line)
+	case "deleteRange":
+		return n.deleteRange(line, end)
+	case "deleteSpan":
+		return n.deleteSpan(line, step.Column, end, step.EndColumn)
+	case "cut":
+		return n.cut(line, step.Column, end, step.EndColumn)
+	case "copyRange":
+		return n.copyRange(line, end)
+	case "copyRangeTo":
+		return n.copyRangeTo(line, end, registerRune(step.Register))
+	case "paste":
+		return n.paste(line)
+	case "pasteFrom":
+		return n.pasteFrom(line, registerRune(step.Register))
+	case "pasteRecent":
+		return n.pasteRecent(line, step.Index)
+	case "moveRange":
+		return n.moveRange(line, end, to)
+	case "replaceAll":
+		var opts FindOptions
+		if step.Options != nil {
+			opts = *step.Options
+		}
+		if opts.StartLine != 0 {
+			opts.StartLine += shift
+		}
+		if opts.EndLine != 0 {
+			opts.EndLine += shift
+		}
+		_, err := n.ReplaceAll(step.Pattern, step.Replacement, opts)
+		return err
+	case "applyPatch":
+		_, err := n.ApplyPatch(step.Patch, step.Fuzz)
+		return err
+	}
+	return n.fail(fmt.Errorf("%w %q", ErrUnknownStep, step.Op))
+}
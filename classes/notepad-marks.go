@@ -0,0 +1,147 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoMark      = errors.New("mark is not set")
+	ErrMarkDeleted = errors.New("the line of the mark was deleted")
+	ErrInvalidMark = errors.New("invalid mark")
+)
+
+func isMark(name rune) bool {
+	return name >= 'a' && name <= 'z'
+}
+
+// SetMark puts mark a-z on a line, like vim's ma. The mark follows the line as lines are
+// inserted or deleted above it.
+func (n *Notepad) SetMark(name rune, line int) error {
+	if !isMark(name) {
+		return n.fail(fmt.Errorf("%w %q", ErrInvalidMark, name))
+	}
+	if err := n.checkLine(line); err != nil {
+		return n.fail(err)
+	}
+	if n.marks == nil {
+		n.marks = make(map[rune]int)
+	}
+	n.marks[name] = line
+	return nil
+}
+
+// Mark returns the line a mark is on
+func (n *Notepad) Mark(name rune) (int, error) {
+	line, ok := n.marks[name]
+	if !ok {
+		return 0, fmt.Errorf("mark %q: %w", name, ErrNoMark)
+	}
+	if line == 0 {
+		return 0, fmt.Errorf("mark %q: %w", name, ErrMarkDeleted)
+	}
+	return line, nil
+}
+
+func (n *Notepad) DeleteMark(name rune) {
+	delete(n.marks, name)
+}
+
+// moveMarks keeps marks on their text while count lines at line are replaced by lines. Lines the
+// replacement leaves as they were at either end keep their marks, and so do lines that are only
+// rewritten, like an insert in the middle of a line, pairing changed lines up from the top. Marks on
+// lines that really go away are set to 0, which reads as deleted, and returned with the line they were on.
+func (n *Notepad) moveMarks(line, count int, lines []string) map[rune]int {
+	if len(n.marks) == 0 {
+		return nil
+	}
+	removed := n.allContent.Lines(line-1, line-1+count)
+	same := 0
+	for same < len(removed) && same < len(lines) && removed[same] == lines[same] {
+		same++
+	}
+	sameEnd := 0
+	for sameEnd < len(removed)-same && sameEnd < len(lines)-same &&
+		removed[len(removed)-1-sameEnd] == lines[len(lines)-1-sameEnd] {
+		sameEnd++
+	}
+
+	rewritten := min(len(removed), len(lines)) - same - sameEnd
+	shift := len(lines) - count
+	var dropped map[rune]int
+	for name, at := range n.marks {
+		switch {
+		case at == 0 || at < line+same+rewritten:
+		case at >= line+count-sameEnd:
+			n.marks[name] = at + shift
+		default:
+			if dropped == nil {
+				dropped = make(map[rune]int)
+			}
+			dropped[name] = at
+			n.marks[name] = 0
+		}
+	}
+	return dropped
+}
+
+// restoreMarks puts deleted marks back on their lines once an undo or redo restored them, marks
+// that were set again or removed in the meantime are left alone
+func (n *Notepad) restoreMarks(dropped map[rune]int) {
+	for name, line := range dropped {
+		if at, ok := n.marks[name]; ok && at == 0 {
+			n.marks[name] = line
+		}
+	}
+}
+
+// Address is a line given by number or by mark, for commands that take either
+type Address struct {
+	line int
+	mark rune
+}
+
+func LineAddress(line int) Address {
+	return Address{line: line}
+}
+
+func MarkAddress(name rune) Address {
+	return Address{mark: name}
+}
+
+func (n *Notepad) resolve(address Address) (int, error) {
+	if address.mark != 0 {
+		return n.Mark(address.mark)
+	}
+	return address.line, nil
+}
+
+func (n *Notepad) resolveRange(start, end Address) (int, int, error) {
+	from, err := n.resolve(start)
+	if err != nil {
+		return 0, 0, n.fail(err)
+	}
+	to, err := n.resolve(end)
+	if err != nil {
+		return 0, 0, n.fail(err)
+	}
+	return from, to, nil
+}
+
+// displayRangeAt is displayRange with endpoints that may be marks
+func (n *Notepad) displayRangeAt(start, end Address) error {
+	from, to, err := n.resolveRange(start, end)
+	if err != nil {
+		return err
+	}
+	return n.displayRange(from, to)
+}
+
+// copyRangeAt is copyRange with endpoints that may be marks
+func (n *Notepad) copyRangeAt(start, end Address) error {
+	from, to, err := n.resolveRange(start, end)
+	if err != nil {
+		return err
+	}
+	return n.copyRange(from, to)
+}
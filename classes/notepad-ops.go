@@ -0,0 +1,121 @@
+package classes
+
+// editOp is a reversible edit that keeps only the text it changes instead of a copy of the whole document
+type editOp interface {
+	apply(n *Notepad)
+	revert(n *Notepad)
+	// size is roughly how many bytes the operation keeps alive in the history
+	size() int
+}
+
+// per string header kept by an operation, counted so that many tiny edits still add up
+const opLineOverhead = 16
+
+// appendTextOp adds text to the end of a line
+type appendTextOp struct {
+	line int
+	text string
+}
+
+func (op *appendTextOp) apply(n *Notepad) {
+	n.allContent.SetLine(op.line-1, n.allContent.Line(op.line-1)+op.text)
+}
+
+func (op *appendTextOp) revert(n *Notepad) {
+	current := n.allContent.Line(op.line - 1)
+	n.allContent.SetLine(op.line-1, current[:len(current)-len(op.text)])
+}
+
+func (op *appendTextOp) size() int {
+	return len(op.text) + opLineOverhead
+}
+
+// spliceOp swaps the removed lines starting at line for the inserted ones, a delete only removes and a paste only inserts
+type spliceOp struct {
+	line     int
+	removed  []string
+	inserted []string
+	// marks the last apply or revert deleted along with their lines, the opposite direction brings
+	// the lines and so the marks back
+	dropped map[rune]int
+}
+
+func (op *spliceOp) apply(n *Notepad) {
+	dropped := n.splice(op.line, len(op.removed), op.inserted)
+	n.restoreMarks(op.dropped)
+	op.dropped = dropped
+}
+
+func (op *spliceOp) revert(n *Notepad) {
+	dropped := n.splice(op.line, len(op.inserted), op.removed)
+	n.restoreMarks(op.dropped)
+	op.dropped = dropped
+}
+
+func (op *spliceOp) size() int {
+	return linesSize(op.removed) + linesSize(op.inserted) + opLineOverhead
+}
+
+func linesSize(lines []string) int {
+	total := 0
+	for _, line := range lines {
+		total += len(line) + opLineOverhead
+	}
+	return total
+}
+
+type lineChange struct {
+	line   int
+	before string
+	after  string
+}
+
+// replaceLinesOp rewrites individual lines in place, keeping only the lines it touched
+type replaceLinesOp struct {
+	changes []lineChange
+}
+
+func (op *replaceLinesOp) apply(n *Notepad) {
+	for _, change := range op.changes {
+		n.allContent.SetLine(change.line-1, change.after)
+	}
+}
+
+func (op *replaceLinesOp) revert(n *Notepad) {
+	for _, change := range op.changes {
+		n.allContent.SetLine(change.line-1, change.before)
+	}
+}
+
+func (op *replaceLinesOp) size() int {
+	total := opLineOverhead
+	for _, change := range op.changes {
+		total += len(change.before) + len(change.after) + opLineOverhead
+	}
+	return total
+}
+
+// groupOp applies several operations as a single undo step
+type groupOp struct {
+	ops []editOp
+}
+
+func (op *groupOp) apply(n *Notepad) {
+	for _, inner := range op.ops {
+		inner.apply(n)
+	}
+}
+
+func (op *groupOp) revert(n *Notepad) {
+	for i := len(op.ops) - 1; i >= 0; i-- {
+		op.ops[i].revert(n)
+	}
+}
+
+func (op *groupOp) size() int {
+	total := 0
+	for _, inner := range op.ops {
+		total += inner.size()
+	}
+	return total
+}
@@ -0,0 +1,114 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+type FindOptions struct {
+	Regexp     bool
+	IgnoreCase bool
+	// line range to search, inclusive, 0 leaves that end open
+	StartLine int
+	EndLine   int
+}
+
+// Match is a hit in the document, lines and columns start at 1 and columns count runes
+type Match struct {
+	Line   int
+	Column int
+	Length int
+	Text   string
+}
+
+func (n *Notepad) compileSearch(pattern string, opts FindOptions) (*regexp.Regexp, int, int, error) {
+	if pattern == "" {
+		return nil, 0, 0, n.fail(ErrEmptyPattern)
+	}
+	start, end := opts.StartLine, opts.EndLine
+	if start == 0 {
+		start = 1
+	} else if err := n.checkLine(start); err != nil {
+		return nil, 0, 0, n.fail(err)
+	}
+	if end == 0 {
+		end = n.allContent.Len()
+	} else if err := n.checkLine(end); err != nil {
+		return nil, 0, 0, n.fail(err)
+	}
+	if start > end && opts.EndLine != 0 {
+		return nil, 0, 0, n.fail(fmt.Errorf("%w: %d,%d", ErrEmptyRange, start, end))
+	}
+
+	if !opts.Regexp {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, 0, 0, n.fail(fmt.Errorf("%w: %w", ErrInvalidPattern, err))
+	}
+	return re, start, end, nil
+}
+
+// Find returns every match of pattern, either literal text or a regular expression, in reading order
+func (n *Notepad) Find(pattern string, opts FindOptions) ([]Match, error) {
+	re, start, end, err := n.compileSearch(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for i, text := range n.allContent.Lines(start-1, end) {
+		line := start + i
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			matches = append(matches, Match{
+				Line:   line,
+				Column: utf8.RuneCountInString(text[:loc[0]]) + 1,
+				Length: utf8.RuneCountInString(text[loc[0]:loc[1]]),
+				Text:   text[loc[0]:loc[1]],
+			})
+		}
+	}
+	return matches, nil
+}
+
+// ReplaceAll replaces every match and returns how many there were. With Regexp set the replacement
+// can refer to capture groups as $1 or ${name}, otherwise it is inserted literally.
+// The whole replacement is a single undo step.
+func (n *Notepad) ReplaceAll(pattern, replacement string, opts FindOptions) (int, error) {
+	re, start, end, err := n.compileSearch(pattern, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	op := &replaceLinesOp{}
+	count := 0
+	for i, text := range n.allContent.Lines(start-1, end) {
+		line := start + i
+		found := len(re.FindAllStringIndex(text, -1))
+		if found == 0 {
+			continue
+		}
+		count += found
+
+		var replaced string
+		if opts.Regexp {
+			replaced = re.ReplaceAllString(text, replacement)
+		} else {
+			replaced = re.ReplaceAllLiteralString(text, replacement)
+		}
+		if replaced != text {
+			op.changes = append(op.changes, lineChange{line: line, before: text, after: replaced})
+		}
+	}
+
+	if len(op.changes) > 0 {
+		n.do(op)
+	}
+	n.recordStep(MacroStep{Op: "replaceAll", Pattern: pattern, Replacement: replacement, Options: &opts})
+	return count, nil
+}
@@ -0,0 +1,197 @@
+package classes
+
+// TextStorage holds the lines of a document, indexes start at 0 and ranges are half open
+type TextStorage interface {
+	Len() int
+	Line(i int) string
+	SetLine(i int, text string)
+	// Lines copies out the lines in [start, end)
+	Lines(start, end int) []string
+	// Splice removes count lines at start and puts lines in their place, lines is never retained
+	Splice(start, count int, lines []string)
+}
+
+// SliceStorage keeps the lines in one slice, reads are O(1) but every splice moves the tail of the document
+type SliceStorage struct {
+	lines []string
+}
+
+func NewSliceStorage(lines []string) *SliceStorage {
+	return &SliceStorage{lines: append([]string{}, lines...)}
+}
+
+func (s *SliceStorage) Len() int {
+	return len(s.lines)
+}
+
+func (s *SliceStorage) Line(i int) string {
+	return s.lines[i]
+}
+
+func (s *SliceStorage) SetLine(i int, text string) {
+	s.lines[i] = text
+}
+
+func (s *SliceStorage) Lines(start, end int) []string {
+	return append([]string{}, s.lines[start:end]...)
+}
+
+func (s *SliceStorage) Splice(start, count int, lines []string) {
+	tail := append([]string{}, s.lines[start+count:]...)
+	s.lines = append(append(s.lines[:start], lines...), tail...)
+}
+
+// RopeStorage keeps one tree node per line in an implicit treap, a balanced tree ordered by position
+// where every node knows the size of its subtree. Reading, inserting and deleting lines are O(log n),
+// a splice of k lines is O(k + log n).
+type RopeStorage struct {
+	root *ropeNode
+	seed uint32
+}
+
+type ropeNode struct {
+	text        string
+	priority    uint32
+	size        int
+	left, right *ropeNode
+}
+
+func NewRopeStorage(lines []string) *RopeStorage {
+	r := &RopeStorage{seed: 2463534242}
+	r.root = r.build(lines)
+	return r
+}
+
+// random is a xorshift generator, priorities only need to look random to keep the tree balanced
+func (r *RopeStorage) random() uint32 {
+	r.seed ^= r.seed << 13
+	r.seed ^= r.seed >> 17
+	r.seed ^= r.seed << 5
+	return r.seed
+}
+
+func ropeSize(node *ropeNode) int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+func (node *ropeNode) update() {
+	node.size = ropeSize(node.left) + ropeSize(node.right) + 1
+}
+
+// build makes a balanced tree in O(n) and then sifts priorities down so they form a heap
+func (r *RopeStorage) build(lines []string) *ropeNode {
+	if len(lines) == 0 {
+		return nil
+	}
+	mid := len(lines) / 2
+	node := &ropeNode{text: lines[mid], priority: r.random()}
+	node.left = r.build(lines[:mid])
+	node.right = r.build(lines[mid+1:])
+	node.update()
+
+	for sift := node; ; {
+		larger := sift
+		if sift.left != nil && sift.left.priority > larger.priority {
+			larger = sift.left
+		}
+		if sift.right != nil && sift.right.priority > larger.priority {
+			larger = sift.right
+		}
+		if larger == sift {
+			break
+		}
+		sift.priority, larger.priority = larger.priority, sift.priority
+		sift = larger
+	}
+	return node
+}
+
+// ropeSplit cuts the tree into its first k lines and the rest
+func ropeSplit(node *ropeNode, k int) (*ropeNode, *ropeNode) {
+	if node == nil {
+		return nil, nil
+	}
+	if ropeSize(node.left) < k {
+		left, right := ropeSplit(node.right, k-ropeSize(node.left)-1)
+		node.right = left
+		node.update()
+		return node, right
+	}
+	left, right := ropeSplit(node.left, k)
+	node.left = right
+	node.update()
+	return left, node
+}
+
+func ropeMerge(a, b *ropeNode) *ropeNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = ropeMerge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = ropeMerge(a, b.left)
+	b.update()
+	return b
+}
+
+func (r *RopeStorage) find(i int) *ropeNode {
+	node := r.root
+	for node != nil {
+		leftSize := ropeSize(node.left)
+		switch {
+		case i < leftSize:
+			node = node.left
+		case i == leftSize:
+			return node
+		default:
+			i -= leftSize + 1
+			node = node.right
+		}
+	}
+	return nil
+}
+
+func (r *RopeStorage) Len() int {
+	return ropeSize(r.root)
+}
+
+func (r *RopeStorage) Line(i int) string {
+	return r.find(i).text
+}
+
+func (r *RopeStorage) SetLine(i int, text string) {
+	r.find(i).text = text
+}
+
+func (r *RopeStorage) Lines(start, end int) []string {
+	lines := make([]string, 0, end-start)
+	var walk func(node *ropeNode, offset int)
+	walk = func(node *ropeNode, offset int) {
+		if node == nil || offset >= end || offset+node.size <= start {
+			return
+		}
+		position := offset + ropeSize(node.left)
+		walk(node.left, offset)
+		if position >= start && position < end {
+			lines = append(lines, node.text)
+		}
+		walk(node.right, position+1)
+	}
+	walk(r.root, 0)
+	return lines
+}
+
+func (r *RopeStorage) Splice(start, count int, lines []string) {
+	left, rest := ropeSplit(r.root, start)
+	_, right := ropeSplit(rest, count)
+	r.root = ropeMerge(ropeMerge(left, r.build(lines)), right)
+}
@@ -0,0 +1,69 @@
+package classes
+
+import (
+	"fmt"
+	"testing"
+)
+
+// storageLines is roughly 6 MB of text, about 64 bytes a line
+const storageLines = 100_000
+
+var storageBackends = []struct {
+	name string
+	new  func(lines []string) TextStorage
+}{
+	{"Slice", func(lines []string) TextStorage { return NewSliceStorage(lines) }},
+	{"Rope", func(lines []string) TextStorage { return NewRopeStorage(lines) }},
+}
+
+func storageDocument() []string {
+	lines := make([]string, storageLines)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%08d the quick brown fox jumps over the lazy dog again and again", i)
+	}
+	return lines
+}
+
+func BenchmarkStorageSplice(b *testing.B) {
+	for _, backend := range storageBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			storage := backend.new(storageDocument())
+			inserted := []string{"a new line", "and another one"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// insert two lines somewhere in the middle and delete two, so the size stays the same
+				at := storage.Len()/2 + i%1000
+				storage.Splice(at, 0, inserted)
+				storage.Splice(at+1000, 2, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkStorageLine(b *testing.B) {
+	for _, backend := range storageBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			storage := backend.new(storageDocument())
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = storage.Line((i * 7919) % storage.Len())
+			}
+		})
+	}
+}
+
+func BenchmarkStorageLines(b *testing.B) {
+	for _, backend := range storageBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			storage := backend.new(storageDocument())
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := (i * 7919) % (storage.Len() - 100)
+				_ = storage.Lines(start, start+100)
+			}
+		})
+	}
+}
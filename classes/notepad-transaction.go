@@ -0,0 +1,103 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+var (
+	ErrTransactionOpen   = errors.New("a transaction is already open")
+	ErrNoTransaction     = errors.New("no transaction is open")
+	ErrTransactionFailed = errors.New("an edit in the transaction failed, it was rolled back")
+)
+
+// transaction collects the edits made between Begin and Commit, they are applied right away
+// but only reach the undo history as one step on Commit
+type transaction struct {
+	ops    []editOp
+	failed bool
+	// marks as they were at Begin, marks only move forward so Rollback puts these back
+	marks map[rune]int
+}
+
+// Begin starts grouping edits, undo and redo are refused until Commit or Rollback
+func (n *Notepad) Begin() error {
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	n.tx = &transaction{marks: maps.Clone(n.marks)}
+	return nil
+}
+
+// Commit records the edits since Begin as a single undo step. If any edit in the group failed
+// validation the whole group is rolled back instead and ErrTransactionFailed is returned.
+func (n *Notepad) Commit() error {
+	if n.tx == nil {
+		return ErrNoTransaction
+	}
+	if n.tx.failed {
+		n.Rollback()
+		return ErrTransactionFailed
+	}
+	if len(n.tx.ops) > 0 {
+		op := &groupOp{ops: n.tx.ops}
+		n.logJournal(journalEntry{Do: &journalEdit{At: time.Now(), Op: encodeOp(op)}})
+		n.history.record(op)
+	}
+	n.tx = nil
+	return nil
+}
+
+// Rollback reverts the edits since Begin and puts the marks back where they were, the clipboard
+// keeps anything copied in the meantime
+func (n *Notepad) Rollback() error {
+	if n.tx == nil {
+		return ErrNoTransaction
+	}
+	(&groupOp{ops: n.tx.ops}).revert(n)
+	n.marks = n.tx.marks
+	n.tx = nil
+	return nil
+}
+
+// InTransaction reports whether Begin was called without a Commit or Rollback yet
+func (n *Notepad) InTransaction() bool {
+	return n.tx != nil
+}
+
+// moveRange moves lines start to end so they sit before line to, where Len()+1 means the end,
+// deleting and inserting in one transaction so a single undo puts them back
+func (n *Notepad) moveRange(start, end, to int) error {
+	if err := n.checkRange(start, end); err != nil {
+		return n.fail(err)
+	}
+	if err := n.checkInsertLine(to); err != nil {
+		return n.fail(err)
+	}
+	if to >= start && to <= end+1 {
+		return n.fail(fmt.Errorf("%w: cannot move %d,%d before line %d, inside itself", ErrLineOutOfRange, start, end, to))
+	}
+	// inside an open transaction the move just becomes part of it
+	nested := n.tx != nil
+	if !nested {
+		n.Begin()
+	}
+	resume := n.pauseRecording()
+	moved := n.allContent.Lines(start-1, end)
+	n.deleteRange(start, end)
+	at := to
+	if to > end {
+		at -= len(moved)
+	}
+	n.insertLines(at, moved...)
+	resume()
+	if !nested {
+		if err := n.Commit(); err != nil {
+			return err
+		}
+	}
+	n.recordStep(MacroStep{Op: "moveRange", Line: start, End: end, To: to})
+	return nil
+}
@@ -0,0 +1,219 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrDocumentNotFound = errors.New("no such document in the workspace")
+	ErrDocumentOpen     = errors.New("a document with that name is already open")
+	ErrUnsavedChanges   = errors.New("document has unsaved changes")
+)
+
+// Workspace holds several open documents by name, usually their path, with one of them active.
+// All documents share the workspace clipboard, so lines copied in one can be pasted in another.
+type Workspace struct {
+	documents map[string]*Notepad
+	order     []string // names in the order they were opened
+	active    string
+	clipboard *Clipboard
+}
+
+func NewWorkspace() *Workspace {
+	return &Workspace{documents: make(map[string]*Notepad), clipboard: NewClipboard(DEFAULT_CLIPBOARD_HISTORY)}
+}
+
+// Add puts a notepad in the workspace under name and makes it active. It switches to the shared
+// clipboard, which is not saved next to the document's file since it holds lines of other documents.
+func (w *Workspace) Add(name string, n *Notepad) error {
+	if _, ok := w.documents[name]; ok {
+		return fmt.Errorf("%s: %w", name, ErrDocumentOpen)
+	}
+	n.clipboard = w.clipboard
+	n.sharedClipboard = true
+	w.documents[name] = n
+	w.order = append(w.order, name)
+	w.active = name
+	return nil
+}
+
+// New adds an unsaved document with the given text
+func (w *Workspace) New(name, text string) (*Notepad, error) {
+	n := NewNotepad(text)
+	if err := w.Add(name, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// OpenFile opens a file under its path, registers are shared so the ones saved next to the file are not loaded
+func (w *Workspace) OpenFile(path string) (*Notepad, error) {
+	if _, ok := w.documents[path]; ok {
+		return nil, fmt.Errorf("%s: %w", path, ErrDocumentOpen)
+	}
+	n, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	w.Add(path, n)
+	return n, nil
+}
+
+func (w *Workspace) Get(name string) (*Notepad, error) {
+	n, ok := w.documents[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, ErrDocumentNotFound)
+	}
+	return n, nil
+}
+
+// Close removes a document, refusing if it has unsaved changes unless discard is set. The closed
+// notepad gets a clipboard of its own again, and the next document in opening order becomes active
+// if the closed one was.
+func (w *Workspace) Close(name string, discard bool) error {
+	n, err := w.Get(name)
+	if err != nil {
+		return err
+	}
+	if unsaved(n) && !discard {
+		return fmt.Errorf("%s: %w", name, ErrUnsavedChanges)
+	}
+	if err := n.DisableJournal(); err != nil {
+		return err
+	}
+	n.clipboard = NewClipboard(DEFAULT_CLIPBOARD_HISTORY)
+	n.sharedClipboard = false
+
+	index := slices.Index(w.order, name)
+	w.order = slices.Delete(w.order, index, index+1)
+	delete(w.documents, name)
+	if w.active == name {
+		w.active = ""
+		if len(w.order) > 0 {
+			w.active = w.order[min(index, len(w.order)-1)]
+		}
+	}
+	return nil
+}
+
+// Names lists the open documents in the order they were opened
+func (w *Workspace) Names() []string {
+	return append([]string{}, w.order...)
+}
+
+func (w *Workspace) SetActive(name string) error {
+	if _, err := w.Get(name); err != nil {
+		return err
+	}
+	w.active = name
+	return nil
+}
+
+// Active returns the active document, or nil when the workspace is empty
+func (w *Workspace) Active() *Notepad {
+	return w.documents[w.active]
+}
+
+func (w *Workspace) ActiveName() string {
+	return w.active
+}
+
+func (w *Workspace) GetClipboard() *Clipboard {
+	return w.clipboard
+}
+
+// WorkspaceMatch is a search result together with the document it was found in
+type WorkspaceMatch struct {
+	Document string
+	Match
+}
+
+// Search runs Find on every document in opening order. A line range is cut to the length of each
+// document and documents shorter than where it starts are skipped. Documents the search fails in
+// are left out of the matches and their errors are returned by name, like SaveAll does.
+func (w *Workspace) Search(pattern string, opts FindOptions) ([]WorkspaceMatch, map[string]error) {
+	var results []WorkspaceMatch
+	failed := make(map[string]error)
+	for _, name := range w.order {
+		n := w.documents[name]
+		documentOpts := opts
+		lines := n.allContent.Len()
+		if documentOpts.StartLine > lines {
+			continue
+		}
+		if documentOpts.EndLine > lines {
+			documentOpts.EndLine = lines
+		}
+
+		matches, err := n.Find(pattern, documentOpts)
+		if err != nil {
+			failed[name] = err
+			continue
+		}
+		for _, match := range matches {
+			results = append(results, WorkspaceMatch{Document: name, Match: match})
+		}
+	}
+	return results, failed
+}
+
+// unsaved reports whether closing a document would lose text, which a document that was never
+// given a file does even without edits
+func unsaved(n *Notepad) bool {
+	return n.IsDirty() || n.Path() == ""
+}
+
+// SaveAll saves every unsaved document and returns the error of each one that could not be saved
+// by name, it is empty when everything was saved
+func (w *Workspace) SaveAll() map[string]error {
+	failed := make(map[string]error)
+	for _, name := range w.order {
+		n := w.documents[name]
+		if !unsaved(n) {
+			continue
+		}
+		if err := n.Save(); err != nil {
+			failed[name] = err
+		}
+	}
+	return failed
+}
+
+func NotePadWorkspace() {
+	w := NewWorkspace()
+	todo, _ := w.New("todo", "buy milk\ncall mom\nfix bike")
+	done, _ := w.New("done", "pay rent")
+
+	fmt.Println("** Copying a line in todo and pasting it in done **")
+	todo.copyRange(2, 2)
+	todo.deleteLine(2)
+	done.paste(1)
+	for _, name := range w.Names() {
+		fmt.Printf("%s: %q\n", name, w.documents[name].lines())
+	}
+
+	fmt.Println("** Searching all documents for 'm' **")
+	matches, _ := w.Search("m", FindOptions{})
+	for _, match := range matches {
+		fmt.Printf("%s:%d:%d %s\n", match.Document, match.Line, match.Column, match.Text)
+	}
+
+	fmt.Println("** Searching lines 2 to 5 for 'b', both documents are shorter than that **")
+	matches, _ = w.Search("b", FindOptions{StartLine: 2, EndLine: 5})
+	for _, match := range matches {
+		fmt.Printf("%s:%d:%d %s\n", match.Document, match.Line, match.Column, match.Text)
+	}
+
+	fmt.Println("** Saving all, neither document has a path yet **")
+	failed := w.SaveAll()
+	for _, name := range w.Names() {
+		fmt.Printf("%s: %v\n", name, failed[name])
+	}
+
+	fmt.Println("** Closing the active document refuses while it has unsaved changes **")
+	fmt.Println(w.Close(w.ActiveName(), false))
+	w.Close(w.ActiveName(), true)
+	fmt.Println("active:", w.ActiveName())
+}
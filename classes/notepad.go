@@ -3,17 +3,36 @@ package classes
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type Notepad struct {
-	allContent []string
-	undoStack  [][]string
-	redoStack  [][]string
-	buffer     []string
+	allContent TextStorage
+	history    *undoHistory
+	clipboard  *Clipboard
+	// the clipboard belongs to a workspace, so it is not saved next to the file
+	sharedClipboard bool
+	// why the registers could not be saved next to the file on the last save, nil if they were
+	registersErr error
+	file         notepadFile
+	// history state that matches the file on disk, the document is dirty whenever it is not current
+	savedNode *undoNode
+	tx        *transaction
+	journal   *notepadJournal
+	// line of each mark, 0 once the line was deleted
+	marks     map[rune]int
+	recording *Macro
 }
 
 func NewNotepad(text string) *Notepad {
-	return &Notepad{allContent: split(text, '\n')}
+	return NewNotepadWithStorage(NewRopeStorage(split(text, '\n')))
+}
+
+// NewNotepadWithStorage lets the caller pick how lines are stored, e.g. a SliceStorage for small documents
+func NewNotepadWithStorage(storage TextStorage) *Notepad {
+	n := &Notepad{allContent: storage, history: newUndoHistory(), clipboard: NewClipboard(DEFAULT_CLIPBOARD_HISTORY)}
+	n.savedNode = n.history.current
+	return n
 }
 
 func split(text string, delim rune) []string {
@@ -21,104 +40,132 @@ func split(text string, delim rune) []string {
 }
 
 func (n *Notepad) display() {
-	for _, line := range n.allContent {
+	for _, line := range n.lines() {
 		fmt.Println(line)
 	}
 }
 
-func (n *Notepad) displayRange(start, end int) bool {
-	if start > len(n.allContent) || end > len(n.allContent) || start > end {
-		fmt.Println("Invalid range")
-		return false
+func (n *Notepad) displayRange(start, end int) error {
+	if err := n.checkRange(start, end); err != nil {
+		return err
 	}
 
-	for i := start - 1; i < end; i++ {
-		fmt.Println(n.allContent[i])
+	for _, line := range n.allContent.Lines(start-1, end) {
+		fmt.Println(line)
 	}
-	return true
+	return nil
 }
 
-func (n *Notepad) insert(line int, text string) bool {
-	if line > len(n.allContent) {
-		fmt.Println("The value of line exceeds number of lines in the file")
-		return false
+func (n *Notepad) insert(line int, text string) error {
+	if err := n.checkLine(line); err != nil {
+		return n.fail(err)
 	}
 
-	//creating a deep copy of the allContent slice and then append this copy to the undoStack.
-	//If you only stored a reference to allContent without making a deep copy, changes to allContent would also affect the stored states in the undoStack, because slices in Go are reference types. Thus, you wouldn’t be able to revert accurately.
-	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
-	n.allContent[line-1] += text
-	return true
+	n.do(&appendTextOp{line: line, text: text})
+	n.recordStep(MacroStep{Op: "insert", Line: line, Text: text})
+	return nil
 }
 
-func (n *Notepad) deleteLine(line int) bool {
-	if line > len(n.allContent) {
-		fmt.Println("The value of line exceeds number of lines in the file")
-		return false
+func (n *Notepad) deleteLine(line int) error {
+	if err := n.checkLine(line); err != nil {
+		return n.fail(err)
 	}
 
-	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
+	n.do(&spliceOp{line: line, removed: n.allContent.Lines(line-1, line)})
+	n.recordStep(MacroStep{Op: "deleteLine", Line: line})
+	return nil
+}
+
+func (n *Notepad) deleteRange(start, end int) error {
+	if err := n.checkRange(start, end); err != nil {
+		return n.fail(err)
+	}
 
-	// [:line - 1] is not included in the new slice, [line:] is included
-	n.allContent = append(n.allContent[:line-1], n.allContent[line:]...)
-	return true
+	n.do(&spliceOp{line: start, removed: n.allContent.Lines(start-1, end)})
+	n.recordStep(MacroStep{Op: "deleteRange", Line: start, End: end})
+	return nil
 }
 
-func (n *Notepad) deleteRange(start, end int) bool {
-	if start > len(n.allContent) || end > len(n.allContent) || start > end {
-		fmt.Println("Invalid range")
-		return false
+func (n *Notepad) copyRange(start, end int) error {
+	if err := n.checkRange(start, end); err != nil {
+		return n.fail(err)
 	}
 
-	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
-	n.allContent = append(n.allContent[:start-1], n.allContent[end:]...)
-	return true
+	n.clipboard.yank(0, n.allContent.Lines(start-1, end))
+	n.recordStep(MacroStep{Op: "copyRange", Line: start, End: end})
+	return nil
+}
+
+// paste puts the last copied lines before line, one past the last line pastes at the end
+func (n *Notepad) paste(line int) error {
+	lines, err := n.clipboard.get(0)
+	if err != nil {
+		return n.fail(err)
+	}
+	if err := n.pasteLines(line, lines); err != nil {
+		return err
+	}
+	n.recordStep(MacroStep{Op: "paste", Line: line})
+	return nil
 }
 
-func (n *Notepad) copyRange(start, end int) bool {
-	if start > len(n.allContent) || end > len(n.allContent) || start > end {
-		fmt.Println("Invalid range")
-		return false
+func (n *Notepad) pasteLines(line int, lines []string) error {
+	if err := n.checkInsertLine(line); err != nil {
+		return n.fail(err)
 	}
 
-	n.buffer = append([]string{}, n.allContent[start-1:end]...)
-	return true
+	n.do(&spliceOp{line: line, inserted: append([]string{}, lines...)})
+	return nil
 }
 
-func (n *Notepad) paste(line int) bool {
-	if line > len(n.allContent) {
-		fmt.Println("The value of line exceeds number of lines in the file")
-		return false
+func (n *Notepad) undo() error {
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	if n.history.current == n.history.root {
+		return ErrNothingToUndo
 	}
 
-	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
-	// line - 1 is not included in the new slice,
-	n.allContent = append(n.allContent[:line-1], append(n.buffer, n.allContent[line-1:]...)...)
-	return true
+	n.logJournal(journalEntry{Undo: true})
+	n.history.undo(n)
+	return nil
 }
 
-func (n *Notepad) undo() bool {
-	if len(n.undoStack) == 0 {
-		fmt.Println("Nothing to undo!")
-		return false
+func (n *Notepad) redo() error {
+	if n.tx != nil {
+		return ErrTransactionOpen
+	}
+	if n.history.redoTarget() == nil {
+		return ErrNothingToRedo
 	}
 
-	n.redoStack = append(n.redoStack, append([]string{}, n.allContent...))
-	n.allContent = n.undoStack[len(n.undoStack)-1]
-	n.undoStack = n.undoStack[:len(n.undoStack)-1]
-	return true
+	n.logJournal(journalEntry{Redo: true})
+	n.history.redo(n)
+	return nil
 }
 
-func (n *Notepad) redo() bool {
-	if len(n.redoStack) == 0 {
-		fmt.Println("Nothing to redo!")
-		return false
+// do applies a new edit and records it for undo, inside a transaction it is kept until Commit
+func (n *Notepad) do(op editOp) {
+	if n.tx != nil {
+		op.apply(n)
+		n.tx.ops = append(n.tx.ops, op)
+		return
 	}
+	n.logJournal(journalEntry{Do: &journalEdit{At: time.Now(), Op: encodeOp(op)}})
+	op.apply(n)
+	n.history.record(op)
+}
+
+// splice replaces count lines starting at line with lines and returns the marks deleted with them
+func (n *Notepad) splice(line, count int, lines []string) map[rune]int {
+	dropped := n.moveMarks(line, count, lines)
+	n.allContent.Splice(line-1, count, lines)
+	return dropped
+}
 
-	n.undoStack = append(n.undoStack, append([]string{}, n.allContent...))
-	n.allContent = n.redoStack[len(n.redoStack)-1]
-	n.redoStack = n.redoStack[:len(n.redoStack)-1]
-	return true
+// lines copies out the whole document
+func (n *Notepad) lines() []string {
+	return n.allContent.Lines(0, n.allContent.Len())
 }
 
 func NotePad() {
@@ -141,7 +188,9 @@ func NotePad() {
 	notepad.display()
 	fmt.Println("****************************** 4 *******************************")
 	fmt.Println("** Redoing last move **")
-	notepad.redo()
+	if err := notepad.redo(); err != nil {
+		fmt.Println(err)
+	}
 	fmt.Println("******************************** 5 ****************************")
 	fmt.Println("** Deleting first line **")
 	notepad.deleteLine(1)
@@ -175,4 +224,59 @@ func NotePad() {
 	fmt.Println("** Redoing last move **")
 	notepad.redo()
 	notepad.display()
+	fmt.Println("****************************** 13 ************************")
+	fmt.Println("** Undo tree: undoing the paste, deleting line 1, then going back to the paste branch **")
+	notepad.SetUndoTree(true)
+	pasted := notepad.CurrentSeq()
+	notepad.undo()
+	notepad.deleteLine(1)
+	for _, branch := range notepad.UndoBranches() {
+		fmt.Printf("branch ends at state %d\n", branch.Seq)
+	}
+	notepad.UndoTo(pasted)
+	notepad.display()
+	fmt.Println("****************************** 14 ************************")
+	fmt.Println("** Diffing a copy against the notepad after deleting line 1 and patching the copy **")
+	copied := NewNotepad(strings.Join(notepad.lines(), "\n"))
+	notepad.deleteLine(1)
+	patch := copied.DiffNotepad(notepad)
+	fmt.Print(patch)
+	if _, err := copied.ApplyPatch(patch, 0); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("patched copy matches:", copied.DiffNotepad(notepad) == "")
+	fmt.Println("****************************** 15 ************************")
+	fmt.Println("** Moving line 1 to the end, then undoing the move in one step **")
+	notepad.moveRange(1, 1, notepad.allContent.Len()+1)
+	notepad.display()
+	notepad.undo()
+	notepad.display()
+	fmt.Println("** A transaction with an invalid delete is rolled back on commit **")
+	notepad.Begin()
+	notepad.insert(1, " (edited)")
+	fmt.Println(notepad.deleteLine(10))
+	fmt.Println(notepad.Commit())
+	notepad.display()
+	fmt.Println("****************************** 16 ************************")
+	fmt.Println("** Marking the last line, pasting above it and printing from line 2 to the mark **")
+	notepad.SetMark('a', notepad.allContent.Len())
+	notepad.copyRange(1, 1)
+	notepad.paste(1)
+	notepad.displayRangeAt(LineAddress(2), MarkAddress('a'))
+	fmt.Println("** Deleting the marked line invalidates the mark **")
+	line, _ := notepad.Mark('a')
+	notepad.deleteLine(line)
+	fmt.Println(notepad.displayRangeAt(LineAddress(1), MarkAddress('a')))
+	fmt.Println("****************************** 17 ************************")
+	fmt.Println("** Recording a macro that marks line 1 done, then playing it on the first three lines in one undo step **")
+	notepad.StartRecording("done")
+	notepad.insert(1, " [done]")
+	macro, _ := notepad.StopRecording()
+	notepad.undo()
+	if _, err := notepad.PlayMacro(macro, 3, 1); err != nil {
+		fmt.Println(err)
+	}
+	notepad.display()
+	notepad.undo()
+	notepad.display()
 }
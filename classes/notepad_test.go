@@ -0,0 +1,78 @@
+package classes
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// FuzzNotepadOps runs a program of edits with arbitrary, often out of range, arguments. Every
+// operation has to either work or return an error, never panic, and undoing back to the start
+// has to give the original text again.
+func FuzzNotepadOps(f *testing.F) {
+	f.Add("one\ntwo\nthree", []byte{0, 1, 0, 0, 0, 4, 1, 2, 0, 0, 8, 3, 1, 1, 0}, "o")
+	f.Add("", []byte{1, 0, 0, 0, 0, 2, 255, 128, 0, 0, 3, 1, 1, 0, 0, 4, 2, 0, 0, 0}, "(")
+	f.Add("a\nb\nc\nd", []byte{5, 1, 1, 2, 1, 6, 1, 2, 3, 1, 7, 2, 3, 1, 0, 9, 0, 0, 0, 0, 10, 1, 0, 0, 0}, "[a-c]")
+	f.Add("héllo wörld\n", []byte{5, 1, 3, 1, 0, 6, 1, 2, 1, 9, 7, 1, 127, 1, 0}, "ö")
+
+	f.Fuzz(runNotepadProgram)
+}
+
+// runNotepadProgram reads the program five bytes at a time, an operation and four signed arguments
+func runNotepadProgram(t *testing.T, text string, program []byte, pattern string) {
+	// a pattern that matches everywhere doubles the document on every replace, keep it small
+	if len(program) > 200 {
+		program = program[:200]
+	}
+	n := NewNotepad(text)
+	original := n.lines()
+
+	step := ""
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("%s panicked: %v", step, r)
+		}
+	}()
+
+	for len(program) >= 5 {
+		code := program[0] % 11
+		a, b, c, d := int(int8(program[1])), int(int8(program[2])), int(int8(program[3])), int(int8(program[4]))
+		program = program[5:]
+		step = fmt.Sprintf("operation %d(%d, %d, %d, %d)", code, a, b, c, d)
+
+		switch code {
+		case 0:
+			n.insert(a, pattern)
+		case 1:
+			n.deleteLine(a)
+		case 2:
+			n.deleteRange(a, b)
+		case 3:
+			n.copyRange(a, b)
+		case 4:
+			n.paste(a)
+		case 5:
+			n.insertAt(a, b, pattern)
+		case 6:
+			n.deleteSpan(a, b, c, d)
+		case 7:
+			n.cut(a, b, c, d)
+		case 8:
+			n.moveRange(a, b, c)
+		case 9:
+			n.UndoTo(a)
+		case 10:
+			if linesSize(n.lines()) > 1<<16 {
+				continue
+			}
+			n.ReplaceAll(pattern, "<$0>", FindOptions{Regexp: b%2 == 0, IgnoreCase: c%2 == 0, StartLine: max(a, 0), EndLine: max(d, 0)})
+		}
+	}
+
+	if err := n.UndoTo(0); err != nil {
+		t.Fatalf("undo to the start: %v", err)
+	}
+	if got := n.lines(); !slices.Equal(got, original) {
+		t.Fatalf("undoing everything gave %q, want %q", got, original)
+	}
+}
@@ -0,0 +1,173 @@
+package classes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// BotMove is one option a bot has for the rolled value, Landing is where the dice takes the token
+// and To is where it ends up once snakes and ladders are applied
+type BotMove struct {
+	Token   int
+	From    int
+	Landing int
+	To      int
+}
+
+type BotTurn struct {
+	Player    *Player
+	DiceValue int
+	BoardSize int
+	Moves     []BotMove
+	dice      *Dice
+}
+
+// Intn lets strategies draw from the game's dice so saved games replay the same bot decisions
+func (t *BotTurn) Intn(n int) int {
+	return t.dice.rng.Intn(n)
+}
+
+type BotStrategy interface {
+	Name() string
+	// ChooseMove returns the index of the move to play out of turn.Moves
+	ChooseMove(turn *BotTurn) int
+}
+
+type RandomBot struct{}
+
+func (RandomBot) Name() string {
+	return "random"
+}
+
+func (RandomBot) ChooseMove(turn *BotTurn) int {
+	return turn.Intn(len(turn.Moves))
+}
+
+// GreedyBot always takes the move that ends furthest along the board
+type GreedyBot struct{}
+
+func (GreedyBot) Name() string {
+	return "greedy"
+}
+
+func (GreedyBot) ChooseMove(turn *BotTurn) int {
+	best := 0
+	for i, move := range turn.Moves {
+		if move.To > turn.Moves[best].To {
+			best = i
+		}
+	}
+	return best
+}
+
+// CautiousBot stays away from snakes whenever it can and only then looks at progress
+type CautiousBot struct{}
+
+func (CautiousBot) Name() string {
+	return "cautious"
+}
+
+func (CautiousBot) ChooseMove(turn *BotTurn) int {
+	best := 0
+	for i, move := range turn.Moves {
+		bitten, bestBitten := move.To < move.Landing, turn.Moves[best].To < turn.Moves[best].Landing
+		if bitten != bestBitten {
+			if !bitten {
+				best = i
+			}
+			continue
+		}
+		if move.To > turn.Moves[best].To {
+			best = i
+		}
+	}
+	return best
+}
+
+var botStrategies = map[string]func() BotStrategy{
+	"random":   func() BotStrategy { return RandomBot{} },
+	"greedy":   func() BotStrategy { return GreedyBot{} },
+	"cautious": func() BotStrategy { return CautiousBot{} },
+}
+
+// RegisterBotStrategy makes a custom strategy available to saved games by its name
+func RegisterBotStrategy(name string, factory func() BotStrategy) {
+	botStrategies[name] = factory
+}
+
+func BotStrategyByName(name string) (BotStrategy, bool) {
+	factory, ok := botStrategies[name]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+func NewBotPlayer(name string, strategy BotStrategy) *Player {
+	player := NewPlayer(name)
+	player.SetBotStrategy(strategy)
+	return player
+}
+
+func (p *Player) SetBotStrategy(strategy BotStrategy) {
+	p.bot = strategy
+}
+
+// PlayBots plays every bot turn until it is a human's turn or the game is over,
+// games made only of bots are played to the end
+func (g *Game) PlayBots() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.playBots()
+}
+
+func (g *Game) playBots() {
+	for g.winner == nil && g.players[g.currentTurn].IsBot() {
+		player := g.players[g.currentTurn]
+		turn := &BotTurn{Player: player, DiceValue: g.dice.Roll(), BoardSize: g.boardSize, dice: g.dice}
+		turn.Moves = g.movesFor(player, turn.DiceValue)
+
+		choice := player.GetBotStrategy().ChooseMove(turn)
+		if choice < 0 || choice >= len(turn.Moves) {
+			choice = 0
+		}
+		g.applyMove(player, turn.Moves[choice])
+		g.nextPlayer()
+	}
+}
+
+func SnakesAndLadderVsBots() {
+	playAgainstBots(os.Stdin, os.Stdout)
+}
+
+func playAgainstBots(in io.Reader, out io.Writer) {
+	human := NewPlayer("You")
+	players := []*Player{
+		human,
+		NewBotPlayer("Greedy", GreedyBot{}),
+		NewBotPlayer("Cautious", CautiousBot{}),
+	}
+	snakes := []*Snake{NewSnake(17, 7), NewSnake(54, 34), NewSnake(62, 19), NewSnake(87, 36), NewSnake(98, 79)}
+	ladders := []*Ladder{NewLadder(4, 14), NewLadder(9, 31), NewLadder(28, 84), NewLadder(72, 91)}
+	g := NewGame(snakes, ladders, players)
+
+	scanner := bufio.NewScanner(in)
+	for g.GetWinner() == nil {
+		fmt.Fprint(out, "Press enter to roll (q to quit): ")
+		if !scanner.Scan() || strings.TrimSpace(scanner.Text()) == "q" {
+			fmt.Fprintln(out)
+			return
+		}
+
+		diceVal := g.GetDice().Roll()
+		g.Roll(human, 0, diceVal)
+		fmt.Fprintf(out, "You rolled %d\n", diceVal)
+		for _, player := range g.GetPlayers() {
+			fmt.Fprintf(out, "  %s: %d\n", player.GetName(), player.GetCurrentPosition())
+		}
+	}
+	fmt.Fprintf(out, "The winner is: %s\n", g.GetWinner().GetName())
+}
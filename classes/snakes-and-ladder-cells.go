@@ -0,0 +1,227 @@
+package classes
+
+// CellEffect is the behaviour of a special square, it runs when a player comes to rest on it.
+// Name identifies the effect in saved games and Symbol is how the board renderer draws it.
+type CellEffect interface {
+	Name() string
+	Symbol() string
+	Apply(turn *EffectTurn)
+}
+
+// SafeEffect is implemented by cells that protect whoever stands on them from other players' effects
+type SafeEffect interface {
+	IsSafe() bool
+}
+
+// EffectTurn is what a cell effect is allowed to do to the game, it is only valid during Apply
+type EffectTurn struct {
+	game   *Game
+	player *Player
+	token  int
+}
+
+func (t *EffectTurn) Player() *Player {
+	return t.player
+}
+
+// Token is the token of Player that landed on the cell
+func (t *EffectTurn) Token() int {
+	return t.token
+}
+
+func (t *EffectTurn) Position() int {
+	return t.player.GetTokenPosition(t.token)
+}
+
+func (t *EffectTurn) BoardSize() int {
+	return t.game.boardSize
+}
+
+func (t *EffectTurn) Players() []*Player {
+	return t.game.players
+}
+
+// MoveTo places the landed token on a square without triggering snakes, ladders or other cells,
+// squares where a snake or ladder starts are refused since a token never rests on them
+func (t *EffectTurn) MoveTo(square int) {
+	if square < 0 || square >= t.game.boardSize {
+		return
+	}
+	if _, exists := t.game.snakesAndLadders[square]; exists {
+		return
+	}
+	t.player.SetTokenPosition(t.token, square)
+}
+
+func (t *EffectTurn) RollAgain() {
+	t.game.extraRoll = true
+}
+
+func (t *EffectTurn) SkipNextTurn() {
+	t.player.skipTurns++
+}
+
+// RandomSquare picks any square except the last one from the game's dice
+func (t *EffectTurn) RandomSquare() int {
+	return t.game.dice.rng.Intn(t.game.boardSize-1) + 1
+}
+
+// Leader is the player, other than the current one, with the furthest token that is not home yet.
+// The earliest in turn order wins ties.
+func (t *EffectTurn) Leader() *Player {
+	var leader *Player
+	best := -1
+	for _, player := range t.game.players {
+		token := player.GetLeadingToken(t.game.boardSize)
+		if player == t.player || token == -1 {
+			continue
+		}
+		if position := player.GetTokenPosition(token); position > best {
+			leader, best = player, position
+		}
+	}
+	return leader
+}
+
+func (t *EffectTurn) IsSafeSquare(square int) bool {
+	effect, exists := t.game.cells[square]
+	if !exists {
+		return false
+	}
+	safe, ok := effect.(SafeEffect)
+	return ok && safe.IsSafe()
+}
+
+// SwapWith exchanges the landed token with the other player's leading token, unless that token stands on a safe square
+func (t *EffectTurn) SwapWith(other *Player) bool {
+	if other == nil || other == t.player {
+		return false
+	}
+	token := other.GetLeadingToken(t.game.boardSize)
+	if token == -1 || t.IsSafeSquare(other.GetTokenPosition(token)) {
+		return false
+	}
+	position := t.Position()
+	t.player.SetTokenPosition(t.token, other.GetTokenPosition(token))
+	other.SetTokenPosition(token, position)
+	return true
+}
+
+type SkipTurnCell struct{}
+
+func (SkipTurnCell) Name() string {
+	return "skip-turn"
+}
+
+func (SkipTurnCell) Symbol() string {
+	return "!"
+}
+
+func (SkipTurnCell) Apply(turn *EffectTurn) {
+	turn.SkipNextTurn()
+}
+
+type RollAgainCell struct{}
+
+func (RollAgainCell) Name() string {
+	return "roll-again"
+}
+
+func (RollAgainCell) Symbol() string {
+	return "+"
+}
+
+func (RollAgainCell) Apply(turn *EffectTurn) {
+	turn.RollAgain()
+}
+
+type TeleportCell struct{}
+
+func (TeleportCell) Name() string {
+	return "teleport"
+}
+
+func (TeleportCell) Symbol() string {
+	return "?"
+}
+
+func (TeleportCell) Apply(turn *EffectTurn) {
+	turn.MoveTo(turn.RandomSquare())
+}
+
+// SwapWithLeaderCell only swaps when the leader is actually ahead
+type SwapWithLeaderCell struct{}
+
+func (SwapWithLeaderCell) Name() string {
+	return "swap-with-leader"
+}
+
+func (SwapWithLeaderCell) Symbol() string {
+	return "<>"
+}
+
+func (SwapWithLeaderCell) Apply(turn *EffectTurn) {
+	leader := turn.Leader()
+	if leader != nil && leader.GetTokenPosition(leader.GetLeadingToken(turn.BoardSize())) > turn.Position() {
+		turn.SwapWith(leader)
+	}
+}
+
+type SafeCell struct{}
+
+func (SafeCell) Name() string {
+	return "safe"
+}
+
+func (SafeCell) Symbol() string {
+	return "*"
+}
+
+func (SafeCell) Apply(turn *EffectTurn) {}
+
+func (SafeCell) IsSafe() bool {
+	return true
+}
+
+var cellEffects = map[string]func() CellEffect{
+	"skip-turn":        func() CellEffect { return SkipTurnCell{} },
+	"roll-again":       func() CellEffect { return RollAgainCell{} },
+	"teleport":         func() CellEffect { return TeleportCell{} },
+	"swap-with-leader": func() CellEffect { return SwapWithLeaderCell{} },
+	"safe":             func() CellEffect { return SafeCell{} },
+}
+
+// RegisterCellEffect makes a custom cell available to saved games by its name
+func RegisterCellEffect(name string, factory func() CellEffect) {
+	cellEffects[name] = factory
+}
+
+func CellEffectByName(name string) (CellEffect, bool) {
+	factory, ok := cellEffects[name]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// AddCell puts an effect on a square, the last square and the starts of snakes and ladders can't hold one
+func (g *Game) AddCell(square int, effect CellEffect) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if square < 1 || square >= g.boardSize || effect == nil {
+		return false
+	}
+	if _, exists := g.snakesAndLadders[square]; exists {
+		return false
+	}
+	g.cells[square] = effect
+	return true
+}
+
+func (g *Game) GetCell(square int) (CellEffect, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	effect, exists := g.cells[square]
+	return effect, exists
+}
@@ -0,0 +1,318 @@
+package classes
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrGameNotFound = errors.New("game not found")
+	ErrPlayerBusy   = errors.New("player is already in an active game")
+	ErrNotInLobby   = errors.New("player was not created by this lobby")
+)
+
+type LobbyGame struct {
+	id        int
+	game      *Game
+	createdAt time.Time
+	// unix nanos of the last roll, kept atomic so rolls never need the lobby write lock
+	lastActivity atomic.Int64
+}
+
+func (lg *LobbyGame) GetId() int {
+	return lg.id
+}
+
+// the game itself stays inside the lobby so every roll goes through Lobby.Roll, which retires
+// finished games, these only read from it
+
+func (lg *LobbyGame) GetPlayers() []*Player {
+	return lg.game.GetPlayers()
+}
+
+func (lg *LobbyGame) GetCurrentPlayer() *Player {
+	return lg.game.GetCurrentPlayer()
+}
+
+func (lg *LobbyGame) GetWinner() *Player {
+	return lg.game.GetWinner()
+}
+
+func (lg *LobbyGame) GetWinningTeam() *Team {
+	return lg.game.GetWinningTeam()
+}
+
+func (lg *LobbyGame) GetBoardSize() int {
+	return lg.game.GetBoardSize()
+}
+
+func (lg *LobbyGame) GetDice() *Dice {
+	return lg.game.GetDice()
+}
+
+func (lg *LobbyGame) Render() string {
+	return lg.game.Render()
+}
+
+func (lg *LobbyGame) Save() ([]byte, error) {
+	return lg.game.Save()
+}
+
+func (lg *LobbyGame) GetCreatedAt() time.Time {
+	return lg.createdAt
+}
+
+func (lg *LobbyGame) GetLastActivity() time.Time {
+	return time.Unix(0, lg.lastActivity.Load())
+}
+
+type LobbyStats struct {
+	InProgress      int
+	Finished        int
+	Expired         int
+	AverageDuration time.Duration
+}
+
+type Lobby struct {
+	mu            sync.RWMutex
+	games         map[int]*LobbyGame
+	activePlayers map[int]int // player id -> game id
+	players       PlayerIDs
+	nextGameID    int
+	idleTimeout   time.Duration
+	finished      int
+	expired       int
+	totalDuration time.Duration
+	now           func() time.Time
+}
+
+func NewLobby(idleTimeout time.Duration) *Lobby {
+	return &Lobby{
+		games:         make(map[int]*LobbyGame),
+		activePlayers: make(map[int]int),
+		idleTimeout:   idleTimeout,
+		now:           time.Now,
+	}
+}
+
+// NewPlayer creates a player with an id from the lobby's own allocator, only such players can join its games
+func (l *Lobby) NewPlayer(name string) *Player {
+	return l.players.NewPlayer(name)
+}
+
+func (l *Lobby) NewPlayerWithTokens(name string, tokens int) *Player {
+	return l.players.NewPlayerWithTokens(name, tokens)
+}
+
+// CreateGame registers a new game, every player must come from this lobby and be free of other active games
+func (l *Lobby) CreateGame(snakes []*Snake, ladders []*Ladder, players []*Player) (int, error) {
+	if len(players) == 0 {
+		return 0, fmt.Errorf("a game needs at least one player")
+	}
+	return l.add(NewGame(snakes, ladders, players))
+}
+
+// ResumeGame loads a game saved with Game.Save into the lobby, its players keep their saved ids
+// and the lobby's allocator skips past them
+func (l *Lobby) ResumeGame(data []byte) (int, error) {
+	game, err := loadGame(data, &l.players)
+	if err != nil {
+		return 0, err
+	}
+	if game.GetWinner() != nil {
+		return 0, fmt.Errorf("game is already over")
+	}
+	return l.add(game)
+}
+
+func (l *Lobby) add(game *Game) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seen := make(map[int]bool)
+	for _, player := range game.GetPlayers() {
+		if player.ids != &l.players {
+			return 0, fmt.Errorf("%w: %s", ErrNotInLobby, player.GetName())
+		}
+		if gameID, busy := l.activePlayers[player.GetId()]; busy {
+			return 0, fmt.Errorf("%w: %s is playing game %d", ErrPlayerBusy, player.GetName(), gameID)
+		}
+		if seen[player.GetId()] {
+			return 0, fmt.Errorf("player %s joined the same game twice", player.GetName())
+		}
+		seen[player.GetId()] = true
+	}
+
+	l.nextGameID++
+	now := l.now()
+	lg := &LobbyGame{id: l.nextGameID, game: game, createdAt: now}
+	lg.lastActivity.Store(now.UnixNano())
+
+	l.games[lg.id] = lg
+	for _, player := range game.GetPlayers() {
+		l.activePlayers[player.GetId()] = lg.id
+	}
+	return lg.id, nil
+}
+
+func (l *Lobby) GetGame(gameID int) (*LobbyGame, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	lg, ok := l.games[gameID]
+	return lg, ok
+}
+
+// GetPlayerGame returns the id of the game the player is currently in
+func (l *Lobby) GetPlayerGame(playerID int) (int, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	gameID, ok := l.activePlayers[playerID]
+	return gameID, ok
+}
+
+// Roll plays a turn in one of the lobby games, a game that gets a winner is retired from the lobby
+func (l *Lobby) Roll(gameID int, player *Player, token int, diceValue int) (bool, error) {
+	lg, ok := l.GetGame(gameID)
+	if !ok {
+		return false, ErrGameNotFound
+	}
+
+	// only the game's own lock is held while playing so games never block each other
+	if !lg.game.Roll(player, token, diceValue) {
+		return false, nil
+	}
+	lg.lastActivity.Store(l.now().UnixNano())
+
+	if lg.game.GetWinner() != nil {
+		l.mu.Lock()
+		if l.remove(lg) {
+			l.finished++
+			l.totalDuration += l.now().Sub(lg.createdAt)
+		}
+		l.mu.Unlock()
+	}
+	return true, nil
+}
+
+// EndGame abandons a game without a winner and frees its players
+func (l *Lobby) EndGame(gameID int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lg, ok := l.games[gameID]
+	if !ok {
+		return ErrGameNotFound
+	}
+	l.remove(lg)
+	return nil
+}
+
+// ExpireIdle drops every game that had no roll within the idle timeout and returns how many were dropped
+func (l *Lobby) ExpireIdle() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-l.idleTimeout).UnixNano()
+	count := 0
+	for _, lg := range l.games {
+		if lg.lastActivity.Load() < cutoff && l.remove(lg) {
+			count++
+		}
+	}
+	l.expired += count
+	return count
+}
+
+// StartJanitor expires idle games every interval until the returned stop function is called
+func (l *Lobby) StartJanitor(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				l.ExpireIdle()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+func (l *Lobby) Stats() LobbyStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := LobbyStats{InProgress: len(l.games), Finished: l.finished, Expired: l.expired}
+	if l.finished > 0 {
+		stats.AverageDuration = l.totalDuration / time.Duration(l.finished)
+	}
+	return stats
+}
+
+// remove must be called with the write lock held, it reports false if the game was already gone
+func (l *Lobby) remove(lg *LobbyGame) bool {
+	if _, ok := l.games[lg.id]; !ok {
+		return false
+	}
+	delete(l.games, lg.id)
+	for _, player := range lg.game.GetPlayers() {
+		if l.activePlayers[player.GetId()] == lg.id {
+			delete(l.activePlayers, player.GetId())
+		}
+	}
+	return true
+}
+
+func SnakesAndLadderLobby() {
+	lobby := NewLobby(time.Minute)
+
+	snakes := []*Snake{NewSnake(17, 7), NewSnake(54, 34), NewSnake(87, 36), NewSnake(98, 79)}
+	ladders := []*Ladder{NewLadder(4, 14), NewLadder(21, 42), NewLadder(51, 67), NewLadder(80, 99)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		players := []*Player{lobby.NewPlayer(fmt.Sprintf("Player%dA", i)), lobby.NewPlayer(fmt.Sprintf("Player%dB", i))}
+		gameID, err := lobby.CreateGame(snakes, ladders, players)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lg, _ := lobby.GetGame(gameID)
+			dice := lg.GetDice()
+			for turn := 0; ; turn++ {
+				ok, err := lobby.Roll(gameID, players[turn%len(players)], 0, dice.Roll())
+				if err != nil || (ok && lg.GetWinner() != nil) {
+					return
+				}
+			}
+		}()
+	}
+
+	busy := lobby.NewPlayer("Busy")
+	lobby.CreateGame(snakes, ladders, []*Player{busy})
+	if _, err := lobby.CreateGame(snakes, ladders, []*Player{busy}); err != nil {
+		fmt.Println(err)
+	}
+	if _, err := lobby.CreateGame(snakes, ladders, []*Player{NewPlayer("Outsider")}); err != nil {
+		fmt.Println(err)
+	}
+
+	wg.Wait()
+	stats := lobby.Stats()
+	fmt.Printf("In progress: %d\tFinished: %d\tAverage duration: %s\n", stats.InProgress, stats.Finished, stats.AverageDuration)
+}
@@ -0,0 +1,108 @@
+package classes
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLobbyConcurrentUse is meant for go test -race, games are created and played from many
+// goroutines while others expire idle games and read the stats
+func TestLobbyConcurrentUse(t *testing.T) {
+	const workers, gamesPerWorker = 8, 50
+	lobby := NewLobby(time.Millisecond)
+	snakes := []*Snake{NewSnake(17, 7), NewSnake(54, 34), NewSnake(87, 36), NewSnake(98, 79)}
+	ladders := []*Ladder{NewLadder(4, 14), NewLadder(21, 42), NewLadder(51, 67), NewLadder(80, 99)}
+
+	done := make(chan struct{})
+	var housekeeping sync.WaitGroup
+	housekeeping.Add(1)
+	go func() {
+		defer housekeeping.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				lobby.ExpireIdle()
+				lobby.Stats()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < gamesPerWorker; i++ {
+				gameID, err := lobby.CreateGame(snakes, ladders, []*Player{lobby.NewPlayer("a"), lobby.NewPlayer("b")})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				lg, ok := lobby.GetGame(gameID)
+				if !ok {
+					continue // expired before the first roll
+				}
+				for lg.GetWinner() == nil {
+					if _, err := lobby.Roll(gameID, lg.GetCurrentPlayer(), 0, rng.Intn(6)+1); err != nil {
+						if !errors.Is(err, ErrGameNotFound) {
+							t.Error(err)
+						}
+						break
+					}
+				}
+				if winner := lg.GetWinner(); winner != nil {
+					if _, busy := lobby.GetPlayerGame(winner.GetId()); busy {
+						t.Errorf("winner of game %d is still busy", gameID)
+					}
+				}
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+	close(done)
+	housekeeping.Wait()
+
+	stats := lobby.Stats()
+	if total := stats.InProgress + stats.Finished + stats.Expired; total != workers*gamesPerWorker {
+		t.Errorf("stats %+v account for %d games, want %d", stats, total, workers*gamesPerWorker)
+	}
+}
+
+func TestLobbyRetiresFinishedGames(t *testing.T) {
+	now := time.Unix(0, 0)
+	lobby := NewLobby(time.Minute)
+	lobby.now = func() time.Time { return now }
+
+	player := lobby.NewPlayer("solo")
+	gameID, err := lobby.CreateGame(nil, nil, []*Player{player})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, _ := lobby.GetGame(gameID)
+	for lg.GetWinner() == nil {
+		now = now.Add(time.Second)
+		// never overshoot the last square, that roll would leave the token where it is
+		dice := min(6, lg.GetBoardSize()-player.GetCurrentPosition())
+		if ok, err := lobby.Roll(gameID, player, 0, dice); !ok || err != nil {
+			t.Fatalf("roll refused: %v", err)
+		}
+	}
+
+	now = now.Add(time.Hour)
+	if expired := lobby.ExpireIdle(); expired != 0 {
+		t.Errorf("ExpireIdle() = %d, want the finished game gone already", expired)
+	}
+	stats := lobby.Stats()
+	if stats.InProgress != 0 || stats.Finished != 1 || stats.Expired != 0 {
+		t.Errorf("stats = %+v, want one finished game", stats)
+	}
+	if _, busy := lobby.GetPlayerGame(player.GetId()); busy {
+		t.Error("player still busy after winning")
+	}
+}
@@ -0,0 +1,131 @@
+package classes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Render draws the board as an ASCII grid numbered boustrophedon style, square 1 is bottom left
+// and every other row runs right to left. Snakes are S1 (head) and s1 (tail), ladders are
+// L1 (bottom) and l1 (top), special cells show their effect's symbol and players are P1, P2...
+// in turn order, with a letter per token (P1a, P1b) when they have several. Everything is sorted
+// so the same game always renders the same way.
+func (g *Game) Render() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	markers := make(map[int][]string)
+	var snakeStarts, ladderStarts []int
+	for start, end := range g.snakesAndLadders {
+		if end < start {
+			snakeStarts = append(snakeStarts, start)
+		} else {
+			ladderStarts = append(ladderStarts, start)
+		}
+	}
+	sort.Ints(snakeStarts)
+	sort.Ints(ladderStarts)
+
+	var snakeLegend, ladderLegend, cellLegend, playerLegend []string
+	for i, start := range snakeStarts {
+		end := g.snakesAndLadders[start]
+		markers[start] = append(markers[start], fmt.Sprintf("S%d", i+1))
+		markers[end] = append(markers[end], fmt.Sprintf("s%d", i+1))
+		snakeLegend = append(snakeLegend, fmt.Sprintf("S%d %d->%d", i+1, start, end))
+	}
+	for i, start := range ladderStarts {
+		end := g.snakesAndLadders[start]
+		markers[start] = append(markers[start], fmt.Sprintf("L%d", i+1))
+		markers[end] = append(markers[end], fmt.Sprintf("l%d", i+1))
+		ladderLegend = append(ladderLegend, fmt.Sprintf("L%d %d->%d", i+1, start, end))
+	}
+
+	var cellSquares []int
+	for square := range g.cells {
+		cellSquares = append(cellSquares, square)
+	}
+	sort.Ints(cellSquares)
+	for _, square := range cellSquares {
+		effect := g.cells[square]
+		markers[square] = append(markers[square], effect.Symbol())
+		cellLegend = append(cellLegend, fmt.Sprintf("%s %d %s", effect.Symbol(), square, effect.Name()))
+	}
+
+	tokens := make(map[int][]string)
+	for i, player := range g.players {
+		label := fmt.Sprintf("P%d", i+1)
+		for token, position := range player.tokens {
+			tokenLabel := label
+			if len(player.tokens) > 1 {
+				tokenLabel += string(rune('a' + token))
+			}
+			tokens[position] = append(tokens[position], tokenLabel)
+		}
+
+		description := label + " " + player.GetName()
+		if player.team != nil {
+			description += " (" + player.team.GetName() + ")"
+		}
+		playerLegend = append(playerLegend, description)
+	}
+
+	var legend strings.Builder
+	legend.WriteString("Snakes: " + strings.Join(snakeLegend, ", ") + "\n")
+	legend.WriteString("Ladders: " + strings.Join(ladderLegend, ", ") + "\n")
+	if len(cellLegend) > 0 {
+		legend.WriteString("Cells: " + strings.Join(cellLegend, ", ") + "\n")
+	}
+	legend.WriteString("Players: " + strings.Join(playerLegend, ", ") + "\n")
+	if len(tokens[0]) > 0 {
+		legend.WriteString("Off board: " + strings.Join(tokens[0], " ") + "\n")
+	}
+
+	cols := int(math.Ceil(math.Sqrt(float64(g.boardSize))))
+	rows := (g.boardSize + cols - 1) / cols
+
+	width := len(fmt.Sprint(g.boardSize))
+	for square := 1; square <= g.boardSize; square++ {
+		width = max(width, len(strings.Join(markers[square], " ")), len(strings.Join(tokens[square], " ")))
+	}
+
+	separator := "+" + strings.Repeat(strings.Repeat("-", width+2)+"+", cols) + "\n"
+	var board strings.Builder
+	board.WriteString(separator)
+	for row := rows - 1; row >= 0; row-- {
+		squares := make([]int, cols)
+		for col := 0; col < cols; col++ {
+			square := row*cols + col + 1
+			if row%2 == 1 {
+				square = row*cols + cols - col
+			}
+			if square > g.boardSize {
+				square = 0
+			}
+			squares[col] = square
+		}
+
+		for line := 0; line < 3; line++ {
+			board.WriteString("|")
+			for _, square := range squares {
+				text := ""
+				if square != 0 {
+					switch line {
+					case 0:
+						text = fmt.Sprint(square)
+					case 1:
+						text = strings.Join(markers[square], " ")
+					case 2:
+						text = strings.Join(tokens[square], " ")
+					}
+				}
+				fmt.Fprintf(&board, " %-*s |", width, text)
+			}
+			board.WriteString("\n")
+		}
+		board.WriteString(separator)
+	}
+
+	return board.String() + legend.String()
+}
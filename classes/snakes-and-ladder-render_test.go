@@ -0,0 +1,49 @@
+package classes
+
+import "testing"
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		game func() *Game
+	}{
+		{
+			name: "render-10",
+			game: func() *Game {
+				players := []*Player{NewPlayer("Ann"), NewPlayerWithTokens("Bob", 2)}
+				g := NewGameOfSize(10, []*Snake{NewSnake(9, 2)}, []*Ladder{NewLadder(3, 7)}, players)
+				g.AddCell(5, SkipTurnCell{})
+				players[0].SetCurrentPosition(4)
+				players[1].SetTokenPosition(0, 4)
+				return g
+			},
+		},
+		{
+			name: "render-100",
+			game: func() *Game {
+				players := []*Player{NewPlayer("Robert"), NewPlayer("Stannis"), NewPlayer("Renly")}
+				snakes := []*Snake{NewSnake(17, 7), NewSnake(54, 34), NewSnake(62, 19), NewSnake(98, 79)}
+				ladders := []*Ladder{NewLadder(1, 38), NewLadder(4, 14), NewLadder(28, 84), NewLadder(80, 99)}
+				g := NewGame(snakes, ladders, players)
+				g.AddCell(33, RollAgainCell{})
+				g.AddCell(66, SafeCell{})
+				players[0].SetCurrentPosition(38)
+				players[1].SetCurrentPosition(38)
+				players[2].SetCurrentPosition(100)
+				return g
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.game()
+			// rendering twice must give the same text, map order must not leak into the output
+			first := g.Render()
+			if second := g.Render(); first != second {
+				t.Fatalf("rendering is not deterministic:\n%s\n%s", first, second)
+			}
+			checkGolden(t, tt.name, first)
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package classes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// GAME_STATE_VERSION is bumped whenever the saved layout changes, older versions are still loadable
+const GAME_STATE_VERSION = 4
+
+type jumpState struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type playerState struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Position  int    `json:"position,omitempty"`  // up to version 3, replaced by Tokens
+	Tokens    []int  `json:"tokens,omitempty"`    // since version 4
+	Bot       string `json:"bot,omitempty"`       // since version 2
+	SkipTurns int    `json:"skipTurns,omitempty"` // since version 3
+}
+
+type teamState struct {
+	Name    string `json:"name"`
+	Players []int  `json:"players"`
+	Next    int    `json:"next"`
+}
+
+type cellState struct {
+	Square int    `json:"square"`
+	Effect string `json:"effect"`
+}
+
+type diceState struct {
+	Seed  int64 `json:"seed"`
+	Draws int64 `json:"draws"`
+}
+
+type gameState struct {
+	Version     int           `json:"version"`
+	BoardSize   int           `json:"boardSize"`
+	Snakes      []jumpState   `json:"snakes"`
+	Ladders     []jumpState   `json:"ladders"`
+	Cells       []cellState   `json:"cells,omitempty"` // since version 3
+	Players     []playerState `json:"players"`
+	Teams       []teamState   `json:"teams,omitempty"` // since version 4
+	CurrentTurn int           `json:"currentTurn"`
+	WinnerID    int           `json:"winnerId,omitempty"`
+	Dice        diceState     `json:"dice"`
+}
+
+// Save serializes everything needed to resume the game exactly where it stopped
+func (g *Game) Save() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := gameState{
+		Version:     GAME_STATE_VERSION,
+		BoardSize:   g.boardSize,
+		CurrentTurn: g.currentTurn,
+		Dice:        diceState{Seed: g.dice.GetSeed(), Draws: g.dice.GetDraws()},
+	}
+
+	// snakes always go down and ladders always go up, so the direction tells them apart
+	for start, end := range g.snakesAndLadders {
+		if end < start {
+			state.Snakes = append(state.Snakes, jumpState{Start: start, End: end})
+		} else {
+			state.Ladders = append(state.Ladders, jumpState{Start: start, End: end})
+		}
+	}
+	sort.Slice(state.Snakes, func(i, j int) bool { return state.Snakes[i].Start < state.Snakes[j].Start })
+	sort.Slice(state.Ladders, func(i, j int) bool { return state.Ladders[i].Start < state.Ladders[j].Start })
+
+	for square, effect := range g.cells {
+		state.Cells = append(state.Cells, cellState{Square: square, Effect: effect.Name()})
+	}
+	sort.Slice(state.Cells, func(i, j int) bool { return state.Cells[i].Square < state.Cells[j].Square })
+
+	for _, player := range g.players {
+		ps := playerState{
+			ID:        player.GetId(),
+			Name:      player.GetName(),
+			Tokens:    player.GetTokenPositions(),
+			SkipTurns: player.skipTurns,
+		}
+		if player.IsBot() {
+			ps.Bot = player.GetBotStrategy().Name()
+		}
+		state.Players = append(state.Players, ps)
+	}
+	for _, team := range g.teams {
+		ts := teamState{Name: team.GetName(), Next: team.next}
+		for _, player := range team.GetPlayers() {
+			ts.Players = append(ts.Players, player.GetId())
+		}
+		state.Teams = append(state.Teams, ts)
+	}
+	if g.winner != nil {
+		state.WinnerID = g.winner.GetId()
+	}
+
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// LoadGame rebuilds a game saved with Save, rejecting states that could not have come from a real game.
+// The players keep their saved ids, which the package wide counter then skips so NewPlayer never
+// hands them out again, use Lobby.ResumeGame to keep a loaded game inside a lobby's own ids.
+func LoadGame(data []byte) (*Game, error) {
+	return loadGame(data, &defaultPlayerIDs)
+}
+
+func loadGame(data []byte, ids *PlayerIDs) (*Game, error) {
+	var state gameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid game state: %w", err)
+	}
+	// older versions only had a single token per player
+	if state.Version < 4 {
+		for i := range state.Players {
+			state.Players[i].Tokens = []int{state.Players[i].Position}
+		}
+	}
+	if err := state.validate(); err != nil {
+		return nil, err
+	}
+
+	snakes := make([]*Snake, 0, len(state.Snakes))
+	for _, s := range state.Snakes {
+		snakes = append(snakes, NewSnake(s.Start, s.End))
+	}
+	ladders := make([]*Ladder, 0, len(state.Ladders))
+	for _, l := range state.Ladders {
+		ladders = append(ladders, NewLadder(l.Start, l.End))
+	}
+
+	players := make([]*Player, 0, len(state.Players))
+	byID := make(map[int]*Player)
+	var winner *Player
+	for _, p := range state.Players {
+		player := ids.restore(p.ID, p.Name, p.Tokens)
+		byID[p.ID] = player
+		if p.Bot != "" {
+			strategy, ok := BotStrategyByName(p.Bot)
+			if !ok {
+				return nil, fmt.Errorf("player %d uses unknown bot strategy %q", p.ID, p.Bot)
+			}
+			player.bot = strategy
+		}
+		player.skipTurns = p.SkipTurns
+		if p.ID == state.WinnerID {
+			winner = player
+		}
+		players = append(players, player)
+	}
+
+	g := NewGameOfSize(state.BoardSize, snakes, ladders, players)
+	for _, ts := range state.Teams {
+		members := make([]*Player, 0, len(ts.Players))
+		for _, id := range ts.Players {
+			members = append(members, byID[id])
+		}
+		team := NewTeam(ts.Name, members...)
+		team.next = ts.Next
+		g.teams = append(g.teams, team)
+	}
+	for _, cell := range state.Cells {
+		effect, ok := CellEffectByName(cell.Effect)
+		if !ok {
+			return nil, fmt.Errorf("square %d has unknown cell effect %q", cell.Square, cell.Effect)
+		}
+		g.cells[cell.Square] = effect
+	}
+	g.currentTurn = state.CurrentTurn
+	g.winner = winner
+	g.dice = restoreDice(state.Dice.Seed, state.Dice.Draws)
+	return g, nil
+}
+
+func (s *gameState) validate() error {
+	if s.Version < 1 || s.Version > GAME_STATE_VERSION {
+		return fmt.Errorf("unsupported game state version %d", s.Version)
+	}
+	if s.BoardSize < 2 {
+		return fmt.Errorf("invalid board size %d", s.BoardSize)
+	}
+
+	starts := make(map[int]bool)
+	ends := make(map[int]bool)
+	for _, jump := range append(append([]jumpState{}, s.Snakes...), s.Ladders...) {
+		if jump.Start < 1 || jump.Start >= s.BoardSize || jump.End < 1 || jump.End > s.BoardSize {
+			return fmt.Errorf("jump %d -> %d is outside the board", jump.Start, jump.End)
+		}
+		if starts[jump.Start] {
+			return fmt.Errorf("more than one jump starts at square %d", jump.Start)
+		}
+		starts[jump.Start] = true
+		ends[jump.End] = true
+	}
+	for _, snake := range s.Snakes {
+		if snake.End >= snake.Start {
+			return fmt.Errorf("snake %d -> %d does not go down", snake.Start, snake.End)
+		}
+	}
+	for _, ladder := range s.Ladders {
+		if ladder.End <= ladder.Start {
+			return fmt.Errorf("ladder %d -> %d does not go up", ladder.Start, ladder.End)
+		}
+	}
+
+	cells := make(map[int]bool)
+	for _, cell := range s.Cells {
+		if cell.Square < 1 || cell.Square >= s.BoardSize || starts[cell.Square] || cells[cell.Square] {
+			return fmt.Errorf("invalid special cell at square %d", cell.Square)
+		}
+		cells[cell.Square] = true
+	}
+
+	if len(s.Players) == 0 {
+		return fmt.Errorf("game has no players")
+	}
+	ids := make(map[int]bool)
+	for _, p := range s.Players {
+		if p.ID < 1 || ids[p.ID] {
+			return fmt.Errorf("invalid or duplicate player id %d", p.ID)
+		}
+		ids[p.ID] = true
+		if len(p.Tokens) == 0 {
+			return fmt.Errorf("player %d has no tokens", p.ID)
+		}
+		home := true
+		for _, position := range p.Tokens {
+			if position < 0 || position > s.BoardSize {
+				return fmt.Errorf("player %d has a token at square %d, outside the board", p.ID, position)
+			}
+			// a jump is taken as soon as a token lands on its start, so a token only rests there when
+			// another jump dropped it on that square
+			if starts[position] && !ends[position] {
+				return fmt.Errorf("player %d has a token resting at the start of a jump on square %d", p.ID, position)
+			}
+			home = home && position == s.BoardSize
+		}
+		if p.ID == s.WinnerID && !home {
+			return fmt.Errorf("winner %d still has tokens on the board", p.ID)
+		}
+		// the game ends with the first player to bring every token home, so that player is the
+		// winner and nobody else can be home too
+		if home && p.ID != s.WinnerID {
+			return fmt.Errorf("player %d has every token home but is not the winner", p.ID)
+		}
+		if p.SkipTurns < 0 {
+			return fmt.Errorf("player %d has a negative number of turns to skip", p.ID)
+		}
+	}
+	if s.CurrentTurn < 0 || s.CurrentTurn >= len(s.Players) {
+		return fmt.Errorf("turn index %d out of range for %d players", s.CurrentTurn, len(s.Players))
+	}
+	if s.WinnerID != 0 && !ids[s.WinnerID] {
+		return fmt.Errorf("winner %d is not one of the players", s.WinnerID)
+	}
+	if err := s.validateTeams(ids); err != nil {
+		return err
+	}
+	if s.Dice.Draws < 0 {
+		return fmt.Errorf("invalid dice draw count %d", s.Dice.Draws)
+	}
+	return nil
+}
+
+// validateTeams checks every player is in exactly one team and the turn sits with the current team's next member
+func (s *gameState) validateTeams(ids map[int]bool) error {
+	if len(s.Teams) == 0 {
+		return nil
+	}
+
+	inTeam := make(map[int]bool)
+	currentID := s.Players[s.CurrentTurn].ID
+	for _, team := range s.Teams {
+		if len(team.Players) == 0 {
+			return fmt.Errorf("team %s has no players", team.Name)
+		}
+		if team.Next < 0 || team.Next >= len(team.Players) {
+			return fmt.Errorf("team %s has next member %d out of range", team.Name, team.Next)
+		}
+		for i, id := range team.Players {
+			if !ids[id] || inTeam[id] {
+				return fmt.Errorf("team %s lists unknown or already placed player %d", team.Name, id)
+			}
+			inTeam[id] = true
+			if id == currentID && i != team.Next {
+				return fmt.Errorf("player %d holds the turn but is not next in team %s", id, team.Name)
+			}
+		}
+	}
+	if len(inTeam) != len(ids) {
+		return fmt.Errorf("every player of a team game must belong to a team")
+	}
+	return nil
+}
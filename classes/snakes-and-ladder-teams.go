@@ -0,0 +1,89 @@
+package classes
+
+import "fmt"
+
+// Team players share one slot in the turn order, the team's members take that slot in rotation
+// and the whole team wins as soon as any member brings all of their tokens home
+type Team struct {
+	name    string
+	players []*Player
+	next    int // member playing the team's next turn
+}
+
+func NewTeam(name string, players ...*Player) *Team {
+	team := &Team{name: name, players: players}
+	for _, player := range players {
+		player.team = team
+	}
+	return team
+}
+
+func (t *Team) GetName() string {
+	return t.name
+}
+
+func (t *Team) GetPlayers() []*Player {
+	return t.players
+}
+
+func NewTeamGame(boardSize int, snakes []*Snake, ladders []*Ladder, teams []*Team) *Game {
+	var playing []*Team
+	for _, team := range teams {
+		if len(team.players) > 0 {
+			playing = append(playing, team)
+		}
+	}
+
+	// players are listed the way turns first come round: every team's first member, then every second member...
+	var players []*Player
+	for member := 0; len(players) < countTeamPlayers(playing); member++ {
+		for _, team := range playing {
+			if member < len(team.players) {
+				players = append(players, team.players[member])
+			}
+		}
+	}
+
+	g := NewGameOfSize(boardSize, snakes, ladders, players)
+	g.teams = playing
+	return g
+}
+
+func countTeamPlayers(teams []*Team) int {
+	count := 0
+	for _, team := range teams {
+		count += len(team.players)
+	}
+	return count
+}
+
+func (g *Game) GetTeams() []*Team {
+	return g.teams
+}
+
+func (g *Game) GetWinningTeam() *Team {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.winner == nil {
+		return nil
+	}
+	return g.winner.team
+}
+
+func SnakesAndLadderTeams() {
+	bot := func(name string, strategy BotStrategy) *Player {
+		player := NewPlayerWithTokens(name, 2)
+		player.SetBotStrategy(strategy)
+		return player
+	}
+	starks := NewTeam("Stark", bot("Robb", GreedyBot{}), bot("Arya", CautiousBot{}))
+	lannisters := NewTeam("Lannister", bot("Jaime", RandomBot{}), bot("Tyrion", CautiousBot{}))
+
+	snakes := []*Snake{NewSnake(17, 7), NewSnake(54, 34), NewSnake(62, 19), NewSnake(87, 36), NewSnake(98, 79)}
+	ladders := []*Ladder{NewLadder(4, 14), NewLadder(9, 31), NewLadder(28, 84), NewLadder(72, 91)}
+	g := NewTeamGame(BOARD_SIZE, snakes, ladders, []*Team{starks, lannisters})
+	g.PlayBots()
+
+	fmt.Print(g.Render())
+	fmt.Printf("The winner is: %s of team %s\n", g.GetWinner().GetName(), g.GetWinningTeam().GetName())
+}
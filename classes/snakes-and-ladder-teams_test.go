@@ -0,0 +1,126 @@
+package classes
+
+import "testing"
+
+func TestTeamTurnOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		teams [][]string
+		want  []string
+	}{
+		{name: "uneven teams", teams: [][]string{{"a1", "a2"}, {"b1"}}, want: []string{"a1", "b1", "a2", "b1", "a1", "b1"}},
+		{name: "even teams", teams: [][]string{{"a1", "a2"}, {"b1", "b2"}}, want: []string{"a1", "b1", "a2", "b2", "a1", "b1"}},
+		{name: "three teams", teams: [][]string{{"a1"}, {"b1", "b2", "b3"}, {"c1", "c2"}}, want: []string{"a1", "b1", "c1", "a1", "b2", "c2", "a1", "b3", "c1"}},
+		{name: "empty team skipped", teams: [][]string{{"a1", "a2"}, {}, {"c1"}}, want: []string{"a1", "c1", "a2", "c1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var teams []*Team
+			for i, names := range tt.teams {
+				var players []*Player
+				for _, name := range names {
+					players = append(players, NewPlayer(name))
+				}
+				teams = append(teams, NewTeam(string(rune('A'+i)), players...))
+			}
+			// a board too large to finish keeps every roll a plain move
+			g := NewTeamGame(1000, nil, nil, teams)
+			for i, want := range tt.want {
+				current := g.GetCurrentPlayer()
+				if current.GetName() != want {
+					t.Fatalf("turn %d: got %s, want %s", i+1, current.GetName(), want)
+				}
+				if !g.Roll(current, 0, 1) {
+					t.Fatalf("turn %d: roll by %s refused", i+1, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiTokenWin(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokens     []int // starting positions of the rolling player's tokens
+		token      int
+		dice       int
+		wantOk     bool
+		wantWinner bool
+	}{
+		{name: "first token home, second still out", tokens: []int{8, 3}, token: 0, dice: 2, wantOk: true, wantWinner: false},
+		{name: "last token home", tokens: []int{10, 8}, token: 1, dice: 2, wantOk: true, wantWinner: true},
+		{name: "overshoot stays put", tokens: []int{10, 8}, token: 1, dice: 3, wantOk: true, wantWinner: false},
+		{name: "token already home is refused", tokens: []int{10, 8}, token: 0, dice: 1, wantOk: false, wantWinner: false},
+		{name: "token out of range is refused", tokens: []int{1, 1}, token: 2, dice: 1, wantOk: false, wantWinner: false},
+		{name: "single token", tokens: []int{9}, token: 0, dice: 1, wantOk: true, wantWinner: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := NewPlayerWithTokens("p", len(tt.tokens))
+			for token, position := range tt.tokens {
+				player.SetTokenPosition(token, position)
+			}
+			g := NewGameOfSize(10, nil, nil, []*Player{player, NewPlayer("other")})
+
+			if ok := g.Roll(player, tt.token, tt.dice); ok != tt.wantOk {
+				t.Fatalf("Roll returned %v, want %v", ok, tt.wantOk)
+			}
+			if won := g.GetWinner() == player; won != tt.wantWinner {
+				t.Fatalf("winner is %v, want player to win: %v", g.GetWinner(), tt.wantWinner)
+			}
+			if !tt.wantOk && g.GetCurrentPlayer() != player {
+				t.Fatalf("a refused roll must not pass the turn")
+			}
+		})
+	}
+}
+
+func TestGetWinningTeam(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions map[string]int // the named player has one token home and the other on this square
+		before    []string       // players rolling a plain move first, to hand the turn to the roller
+		roller    string
+		want      string
+	}{
+		{name: "no winner yet", positions: map[string]int{}, roller: "a1", want: ""},
+		{name: "first team wins", positions: map[string]int{"a1": 9}, roller: "a1", want: "A"},
+		{name: "other team wins", positions: map[string]int{"b1": 9}, before: []string{"a1"}, roller: "b1", want: "B"},
+		{name: "second member wins for the team", positions: map[string]int{"a2": 9}, before: []string{"a1", "b1"}, roller: "a2", want: "A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := map[string]*Player{}
+			for _, name := range []string{"a1", "a2", "b1"} {
+				players[name] = NewPlayerWithTokens(name, 2)
+				if position, ok := tt.positions[name]; ok {
+					players[name].SetTokenPosition(0, 10)
+					players[name].SetTokenPosition(1, position)
+				}
+			}
+			g := NewTeamGame(10, nil, nil, []*Team{
+				NewTeam("A", players["a1"], players["a2"]),
+				NewTeam("B", players["b1"]),
+			})
+			for _, name := range tt.before {
+				if !g.Roll(players[name], 0, 1) {
+					t.Fatalf("%s could not roll", name)
+				}
+			}
+			if !g.Roll(players[tt.roller], 1, 1) {
+				t.Fatalf("%s could not roll", tt.roller)
+			}
+
+			got := ""
+			if team := g.GetWinningTeam(); team != nil {
+				got = team.GetName()
+			}
+			if got != tt.want {
+				t.Fatalf("winning team %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"time"
 )
 
+const BOARD_SIZE = 100
+
 type Snake struct {
 	start, end int
 }
@@ -23,27 +26,106 @@ func NewLadder(start, end int) *Ladder {
 }
 
 type Player struct {
-	id              int
-	name            string
-	currentPosition int
+	id     int
+	name   string
+	tokens []int
+	bot    BotStrategy
+	team   *Team
+	// turns this player still has to sit out because of a skip-turn cell
+	skipTurns int
+	// the allocator the id came from, ids are only unique within one allocator
+	ids *PlayerIDs
+}
+
+// PlayerIDs hands out player ids. Standalone games share the package wide one behind NewPlayer,
+// every Lobby has its own so its games never depend on players created elsewhere.
+type PlayerIDs struct {
+	mu   sync.Mutex
+	last int
+}
+
+var defaultPlayerIDs PlayerIDs
+
+func (ids *PlayerIDs) NewPlayer(name string) *Player {
+	return ids.NewPlayerWithTokens(name, 1)
 }
 
-var playerIDCounter int
-var playerIDMutex sync.Mutex
+func (ids *PlayerIDs) NewPlayerWithTokens(name string, tokens int) *Player {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	ids.last++
+	if tokens < 1 {
+		tokens = 1
+	}
+	return &Player{id: ids.last, name: name, tokens: make([]int, tokens), ids: ids}
+}
+
+// restore recreates a player under a saved id and makes sure players created afterwards never reuse it
+func (ids *PlayerIDs) restore(id int, name string, tokens []int) *Player {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	if id > ids.last {
+		ids.last = id
+	}
+	return &Player{id: id, name: name, tokens: append([]int{}, tokens...), ids: ids}
+}
 
 func NewPlayer(name string) *Player {
-	playerIDMutex.Lock()
-	defer playerIDMutex.Unlock()
-	playerIDCounter++
-	return &Player{id: playerIDCounter, name: name, currentPosition: 0}
+	return defaultPlayerIDs.NewPlayer(name)
+}
+
+// NewPlayerWithTokens creates a player that moves one of several tokens per roll and wins once all of them are home
+func NewPlayerWithTokens(name string, tokens int) *Player {
+	return defaultPlayerIDs.NewPlayerWithTokens(name, tokens)
 }
 
+// GetCurrentPosition is the position of the player's first token
 func (p *Player) GetCurrentPosition() int {
-	return p.currentPosition
+	return p.tokens[0]
 }
 
 func (p *Player) SetCurrentPosition(position int) {
-	p.currentPosition = position
+	p.tokens[0] = position
+}
+
+func (p *Player) GetTokenCount() int {
+	return len(p.tokens)
+}
+
+func (p *Player) GetTokenPosition(token int) int {
+	return p.tokens[token]
+}
+
+func (p *Player) SetTokenPosition(token, position int) {
+	p.tokens[token] = position
+}
+
+func (p *Player) GetTokenPositions() []int {
+	return append([]int{}, p.tokens...)
+}
+
+// GetLeadingToken is the furthest token that still has to reach the last square, or -1 if all are home
+func (p *Player) GetLeadingToken(boardSize int) int {
+	leading := -1
+	for token, position := range p.tokens {
+		if position < boardSize && (leading == -1 || position > p.tokens[leading]) {
+			leading = token
+		}
+	}
+	return leading
+}
+
+func (p *Player) allTokensHome(boardSize int) bool {
+	for _, position := range p.tokens {
+		if position != boardSize {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Player) GetTeam() *Team {
+	return p.team
 }
 
 func (p *Player) GetName() string {
@@ -54,15 +136,81 @@ func (p *Player) GetId() int {
 	return p.id
 }
 
+func (p *Player) IsBot() bool {
+	return p.bot != nil
+}
+
+func (p *Player) GetBotStrategy() BotStrategy {
+	return p.bot
+}
+
+// countingSource remembers how many values were drawn from the seeded source,
+// so the exact dice sequence can be resumed after a save
+type countingSource struct {
+	src   rand.Source
+	draws int64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+type Dice struct {
+	seed   int64
+	source *countingSource
+	rng    *rand.Rand
+}
+
+func NewDice(seed int64) *Dice {
+	source := &countingSource{src: rand.NewSource(seed)}
+	return &Dice{seed: seed, source: source, rng: rand.New(source)}
+}
+
+// restoreDice rebuilds a dice from its seed and fast-forwards it past the values already drawn
+func restoreDice(seed, draws int64) *Dice {
+	d := NewDice(seed)
+	for i := int64(0); i < draws; i++ {
+		d.source.Int63()
+	}
+	return d
+}
+
+func (d *Dice) Roll() int {
+	return d.rng.Intn(6) + 1
+}
+
+func (d *Dice) GetSeed() int64 {
+	return d.seed
+}
+
+func (d *Dice) GetDraws() int64 {
+	return d.source.draws
+}
+
 type Game struct {
 	players          []*Player
+	teams            []*Team
 	currentTurn      int
 	winner           *Player
 	snakesAndLadders map[int]int
+	boardSize        int
+	dice             *Dice
+	cells            map[int]CellEffect
+	extraRoll        bool
 	mu               sync.Mutex
 }
 
 func NewGame(snakes []*Snake, ladders []*Ladder, players []*Player) *Game {
+	return NewGameOfSize(BOARD_SIZE, snakes, ladders, players)
+}
+
+func NewGameOfSize(boardSize int, snakes []*Snake, ladders []*Ladder, players []*Player) *Game {
 	snakesAndLadders := make(map[int]int)
 	for _, snake := range snakes {
 		snakesAndLadders[snake.start] = snake.end
@@ -70,46 +218,139 @@ func NewGame(snakes []*Snake, ladders []*Ladder, players []*Player) *Game {
 	for _, ladder := range ladders {
 		snakesAndLadders[ladder.start] = ladder.end
 	}
-	return &Game{players: players, snakesAndLadders: snakesAndLadders}
+	return &Game{
+		players:          players,
+		snakesAndLadders: snakesAndLadders,
+		boardSize:        boardSize,
+		dice:             NewDice(time.Now().UnixNano()),
+		cells:            make(map[int]CellEffect),
+	}
 }
 
-func (g *Game) Roll(player *Player, diceValue int) bool {
+// Roll moves one of the player's tokens, after which any bots whose turn follows are played automatically.
+// The token must not be home yet, single token players always pass 0.
+func (g *Game) Roll(player *Player, token int, diceValue int) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	if g.winner != nil || diceValue > 6 || diceValue < 1 || g.players[g.currentTurn].GetId() != player.GetId() {
 		return false
 	}
+	current := g.players[g.currentTurn]
+	if token < 0 || token >= current.GetTokenCount() || current.GetTokenPosition(token) == g.boardSize {
+		return false
+	}
 
-	destination := g.players[g.currentTurn].GetCurrentPosition() + diceValue
-	if destination <= 100 {
-		if end, exists := g.snakesAndLadders[destination]; exists {
-			g.players[g.currentTurn].SetCurrentPosition(end)
-		} else {
-			g.players[g.currentTurn].SetCurrentPosition(destination)
+	g.applyMove(current, g.moveFor(current, token, diceValue))
+	g.nextPlayer()
+	g.playBots()
+	return true
+}
+
+// movesFor lists one move per token that is not home yet
+func (g *Game) movesFor(player *Player, diceValue int) []BotMove {
+	var moves []BotMove
+	for token, position := range player.tokens {
+		if position != g.boardSize {
+			moves = append(moves, g.moveFor(player, token, diceValue))
+		}
+	}
+	return moves
+}
+
+func (g *Game) moveFor(player *Player, token int, diceValue int) BotMove {
+	from := player.GetTokenPosition(token)
+	move := BotMove{Token: token, From: from, Landing: from + diceValue, To: from}
+	if move.Landing <= g.boardSize {
+		move.To = move.Landing
+		if end, exists := g.snakesAndLadders[move.Landing]; exists {
+			move.To = end
 		}
 	}
+	return move
+}
 
-	if destination == 100 {
-		g.winner = g.players[g.currentTurn]
+func (g *Game) applyMove(player *Player, move BotMove) {
+	player.SetTokenPosition(move.Token, move.To)
+	if player.allTokensHome(g.boardSize) {
+		g.winner = player
+		return
 	}
 
-	g.nextPlayer()
-	return true
+	// special cells only trigger when the token actually comes to rest on them
+	if effect, exists := g.cells[move.To]; exists && move.To != move.From {
+		effect.Apply(&EffectTurn{game: g, player: player, token: move.Token})
+	}
 }
 
+// nextPlayer hands the turn on, unless a cell granted another roll, passing over anyone who has turns to skip
 func (g *Game) nextPlayer() {
-	g.currentTurn = (g.currentTurn + 1) % len(g.players)
+	if g.extraRoll {
+		g.extraRoll = false
+		return
+	}
+	for {
+		g.advanceTurn()
+		player := g.players[g.currentTurn]
+		if player.skipTurns == 0 {
+			return
+		}
+		player.skipTurns--
+	}
+}
+
+// advanceTurn goes round the players in order, or in team games round the teams with
+// each team rotating through its own members
+func (g *Game) advanceTurn() {
+	if len(g.teams) == 0 {
+		g.currentTurn = (g.currentTurn + 1) % len(g.players)
+		return
+	}
+
+	current := g.players[g.currentTurn].team
+	current.next = (current.next + 1) % len(current.players)
+	for i, team := range g.teams {
+		if team == current {
+			next := g.teams[(i+1)%len(g.teams)]
+			g.currentTurn = g.playerIndex(next.players[next.next])
+			return
+		}
+	}
+}
+
+func (g *Game) playerIndex(player *Player) int {
+	for i, p := range g.players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
 }
 
 func (g *Game) GetPlayers() []*Player {
 	return g.players
 }
 
+func (g *Game) GetCurrentPlayer() *Player {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.players[g.currentTurn]
+}
+
 func (g *Game) GetWinner() *Player {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	return g.winner
 }
 
+func (g *Game) GetDice() *Dice {
+	return g.dice
+}
+
+func (g *Game) GetBoardSize() int {
+	return g.boardSize
+}
+
 func SnakesAndLadder() {
 
 	p1 := NewPlayer("Robert")
@@ -141,16 +382,18 @@ func SnakesAndLadder() {
 	players := []*Player{p1, p2, p3}
 
 	g := NewGame(snakes, ladders, players)
+	g.AddCell(5, SkipTurnCell{})
+	g.AddCell(33, RollAgainCell{})
+	g.AddCell(47, TeleportCell{})
+	g.AddCell(58, SwapWithLeaderCell{})
+	g.AddCell(66, SafeCell{})
 
+	dice := g.GetDice()
 	for g.GetWinner() == nil {
-		diceVal := rand.Intn(6) + 1
-		g.Roll(p1, diceVal)
-		diceVal = rand.Intn(6) + 1
-		g.Roll(p2, diceVal)
-		diceVal = rand.Intn(6) + 1
-		g.Roll(p3, diceVal)
+		g.Roll(g.GetCurrentPlayer(), 0, dice.Roll())
 	}
 
+	fmt.Print(g.Render())
 	fmt.Printf("The winner is: %s\n", g.GetWinner().GetName())
 	fmt.Print("All Scores: ")
 	for _, player := range g.GetPlayers() {
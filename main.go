@@ -1,13 +1,31 @@
 package main
 
-import "github.com/aman1117/go-lld/classes"
+import (
+	"os"
+
+	"github.com/aman1117/go-lld/classes"
+)
 
 func main() {
 
+	// go run . ed [file] starts the interactive notepad editor
+	if len(os.Args) > 1 && os.Args[1] == "ed" {
+		classes.NotePadEditor(os.Args[2:])
+		return
+	}
+
 	// classes.MeetingScheduler()
 	// classes.SnakesAndLadder()
+	// classes.SnakesAndLadderLobby()
+	// classes.SnakesAndLadderVsBots()
+	// classes.SnakesAndLadderTeams()
 	// classes.NotePad()
+	// classes.NotePadCollab()
+	// classes.NotePadWorkspace()
 	// classes.EmployeeManagement()
+	// classes.EmployeeOrgChart()
+	// classes.EmployeeReorg()
+	// classes.EmployeeNameSearch()
 	// classes.BookCatalogSystem()
 	// classes.SplitwiseExpense()
 	classes.RideSystemClass()